@@ -0,0 +1,27 @@
+package main
+
+import (
+	"net/http"
+
+	"github.com/DanilShapilov/chirpy/internal/database"
+)
+
+// authorizeAdmin checks that the request carries the JWT of an admin.
+// On failure it writes the error response and returns false.
+func (cfg *apiConfig) authorizeAdmin(w http.ResponseWriter, req *http.Request) (database.User, bool) {
+	userID, ok := cfg.authenticate(w, req)
+	if !ok {
+		return database.User{}, false
+	}
+
+	user, err := cfg.db.GetUser(req.Context(), userID)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "Couldn't find user", err)
+		return database.User{}, false
+	}
+	if !user.IsAdmin {
+		respondWithError(w, http.StatusForbidden, "Admin access required", nil)
+		return database.User{}, false
+	}
+	return user, true
+}
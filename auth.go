@@ -0,0 +1,46 @@
+package main
+
+import (
+	"net/http"
+
+	"github.com/DanilShapilov/chirpy/internal/auth"
+	"github.com/google/uuid"
+)
+
+// authenticate checks the JWT of a request and that its user isn't
+// suspended. Every JWT-protected handler starts with it. On failure it
+// writes the error response and returns false.
+func (cfg *apiConfig) authenticate(w http.ResponseWriter, req *http.Request) (uuid.UUID, bool) {
+	token, err := auth.GetBearerToken(req.Header)
+	if err != nil {
+		respondWithError(
+			w,
+			http.StatusUnauthorized,
+			"Couldn't find JWT",
+			err,
+		)
+		return uuid.Nil, false
+	}
+
+	userID, err := cfg.jwtKeys.ValidateJWT(req.Context(), token)
+	if err != nil {
+		respondWithError(
+			w,
+			http.StatusUnauthorized,
+			"Couldn't validate JWT",
+			err,
+		)
+		return uuid.Nil, false
+	}
+
+	suspended, err := cfg.db.IsUserSuspended(req.Context(), userID)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't check user", err)
+		return uuid.Nil, false
+	}
+	if suspended {
+		respondWithError(w, http.StatusForbidden, "Account is suspended", nil)
+		return uuid.Nil, false
+	}
+	return userID, true
+}
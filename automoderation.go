@@ -0,0 +1,168 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/DanilShapilov/chirpy/internal/database"
+	"github.com/DanilShapilov/chirpy/internal/spam"
+	"github.com/google/uuid"
+)
+
+// ruleReports is the rule of decisions made because a chirp got
+// reportHideThreshold reports.
+const ruleReports = "reports"
+
+type ModerationDecision struct {
+	ID            uuid.UUID  `json:"id"`
+	CreatedAt     time.Time  `json:"created_at"`
+	ChirpID       uuid.UUID  `json:"chirp_id"`
+	UserID        uuid.UUID  `json:"user_id"`
+	Rule          string     `json:"rule"`
+	Reason        string     `json:"reason"`
+	AppealStatus  string     `json:"appeal_status"`
+	AppealMessage string     `json:"appeal_message"`
+	AppealedAt    *time.Time `json:"appealed_at"`
+	ResolvedAt    *time.Time `json:"resolved_at"`
+}
+
+func renderModerationDecision(decision database.ModerationDecision) ModerationDecision {
+	res := ModerationDecision{
+		ID:            decision.ID,
+		CreatedAt:     decision.CreatedAt,
+		ChirpID:       decision.ChirpID,
+		UserID:        decision.UserID,
+		Rule:          decision.Rule,
+		Reason:        decision.Reason,
+		AppealStatus:  decision.AppealStatus,
+		AppealMessage: decision.AppealMessage,
+	}
+	if decision.AppealedAt.Valid {
+		res.AppealedAt = &decision.AppealedAt.Time
+	}
+	if decision.ResolvedAt.Valid {
+		res.ResolvedAt = &decision.ResolvedAt.Time
+	}
+	return res
+}
+
+// spamPipelineFromEnv builds the checkers run on every new chirp. A
+// limit of 0 turns its checker off.
+func spamPipelineFromEnv() (*spam.Pipeline, error) {
+	maxDuplicates, err := intFromEnv("SPAM_MAX_DUPLICATES", 2)
+	if err != nil {
+		return nil, err
+	}
+	duplicateWindow, err := durationFromEnv("SPAM_DUPLICATE_WINDOW", 24*time.Hour)
+	if err != nil {
+		return nil, err
+	}
+	maxLinks, err := intFromEnv("SPAM_MAX_LINKS", 3)
+	if err != nil {
+		return nil, err
+	}
+	maxBurst, err := intFromEnv("SPAM_MAX_BURST", 10)
+	if err != nil {
+		return nil, err
+	}
+	burstWindow, err := durationFromEnv("SPAM_BURST_WINDOW", time.Minute)
+	if err != nil {
+		return nil, err
+	}
+
+	var checkers []spam.Checker
+	if maxDuplicates > 0 {
+		checkers = append(checkers, spam.Duplicates{Max: maxDuplicates, Window: duplicateWindow})
+	}
+	if maxLinks > 0 {
+		checkers = append(checkers, spam.Links{Max: maxLinks})
+	}
+	if maxBurst > 0 {
+		checkers = append(checkers, spam.Burst{Max: maxBurst, Window: burstWindow})
+	}
+	return spam.NewPipeline(checkers...), nil
+}
+
+// screenChirp runs the spam checkers on a chirp that was just created
+// or edited with q and hides it when one of them flags it. It returns the
+// decision, or nil when the chirp passed.
+func (cfg *apiConfig) screenChirp(ctx context.Context, q *database.Queries, chirp database.Chirp) (*database.ModerationDecision, error) {
+	in := spam.Input{
+		Chirp: spam.Post{Body: chirp.Body, CreatedAt: chirp.CreatedAt},
+	}
+	if lookback := cfg.spam.Lookback(); lookback > 0 {
+		rows, err := q.GetRecentChirpsByUser(ctx, database.GetRecentChirpsByUserParams{
+			UserID:    chirp.UserID,
+			Since:     chirp.CreatedAt.Add(-lookback),
+			Until:     chirp.CreatedAt,
+			ExcludeID: chirp.ID,
+		})
+		if err != nil {
+			return nil, err
+		}
+		in.Recent = make([]spam.Post, len(rows))
+		for i, row := range rows {
+			in.Recent[i] = spam.Post{Body: row.Body, CreatedAt: row.CreatedAt}
+		}
+	}
+
+	decision, flagged := cfg.spam.Check(in)
+	if !flagged {
+		return nil, nil
+	}
+	return autoHideChirp(ctx, q, chirp.ID, decision)
+}
+
+// checkReportThreshold hides a chirp once it has reportHideThreshold
+// open reports. It returns the decision, or nil when the chirp stays
+// up.
+func (cfg *apiConfig) checkReportThreshold(ctx context.Context, q *database.Queries, chirpID uuid.UUID) (*database.ModerationDecision, error) {
+	if cfg.reportHideThreshold == 0 {
+		return nil, nil
+	}
+	count, err := q.CountOpenChirpReports(ctx, chirpID)
+	if err != nil {
+		return nil, err
+	}
+	if count < int64(cfg.reportHideThreshold) {
+		return nil, nil
+	}
+	return autoHideChirp(ctx, q, chirpID, spam.Decision{
+		Rule:   ruleReports,
+		Reason: fmt.Sprintf("Chirp got %d reports", count),
+	})
+}
+
+// autoHideChirp hides a chirp, closes its open reports and records the
+// decision so the author can appeal it. It returns nil when the chirp
+// is already gone.
+func autoHideChirp(ctx context.Context, q *database.Queries, chirpID uuid.UUID, decision spam.Decision) (*database.ModerationDecision, error) {
+	chirp, err := q.HideChirp(ctx, chirpID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	_, err = q.ResolveChirpReports(ctx, database.ResolveChirpReportsParams{
+		ChirpID: chirp.ID,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	recorded, err := q.CreateModerationDecision(ctx, database.CreateModerationDecisionParams{
+		ChirpID: chirp.ID,
+		UserID:  chirp.UserID,
+		Rule:    decision.Rule,
+		Reason:  decision.Reason,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &recorded, nil
+}
@@ -0,0 +1,315 @@
+package main
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/DanilShapilov/chirpy/internal/database"
+	"github.com/DanilShapilov/chirpy/internal/entities"
+	"github.com/google/uuid"
+)
+
+type ChirpEntity struct {
+	Kind       entities.Kind `json:"kind"`
+	Offset     int           `json:"offset"`
+	Length     int           `json:"length"`
+	Text       string        `json:"text"`
+	UserID     *uuid.UUID    `json:"user_id,omitempty"`
+	URL        string        `json:"url,omitempty"`
+	DisplayURL string        `json:"display_url,omitempty"`
+}
+
+type LinkPreview struct {
+	URL         string `json:"url"`
+	Title       string `json:"title"`
+	Description string `json:"description"`
+	ImageURL    string `json:"image_url"`
+	SiteName    string `json:"site_name"`
+}
+
+// ChirpEmbed is a chirp referenced by a rechirp or a quote. Once the
+// original is deleted only a tombstone with its ID is left.
+type ChirpEmbed struct {
+	ID      uuid.UUID `json:"id"`
+	Deleted bool      `json:"deleted"`
+	Chirp   *Chirp    `json:"chirp"`
+}
+
+// renderChirps converts database chirps into their JSON form. Counters
+// are loaded with one query for the whole slice rather than per chirp.
+// viewerID personalizes the result and may be uuid.Nil for anonymous
+// requests.
+func (cfg *apiConfig) renderChirps(ctx context.Context, chirps []database.Chirp, viewerID uuid.UUID) ([]Chirp, error) {
+	res, err := cfg.renderChirpsWithoutEmbeds(ctx, chirps, viewerID)
+	if err != nil {
+		return nil, err
+	}
+
+	embedIDs := []uuid.UUID{}
+	for _, chirp := range chirps {
+		if chirp.RechirpOf.Valid {
+			embedIDs = append(embedIDs, chirp.RechirpOf.UUID)
+		}
+		if chirp.QuoteOf.Valid {
+			embedIDs = append(embedIDs, chirp.QuoteOf.UUID)
+		}
+	}
+	if len(embedIDs) == 0 {
+		return res, nil
+	}
+
+	// Embedded chirps are rendered one level deep, so a quote of a quote
+	// only carries the ID of the innermost chirp.
+	// Chirps hidden by a block show up as tombstones.
+	originals, err := cfg.db.GetChirpsByIDs(ctx, database.GetChirpsByIDsParams{
+		Ids:      embedIDs,
+		ViewerID: nullViewer(viewerID),
+	})
+	if err != nil {
+		return nil, err
+	}
+	renderedOriginals, err := cfg.renderChirpsWithoutEmbeds(ctx, originals, viewerID)
+	if err != nil {
+		return nil, err
+	}
+	byID := make(map[uuid.UUID]*Chirp, len(renderedOriginals))
+	for i := range renderedOriginals {
+		byID[renderedOriginals[i].ID] = &renderedOriginals[i]
+	}
+
+	for i, chirp := range chirps {
+		if chirp.RechirpOf.Valid {
+			res[i].RechirpOf = chirpEmbed(chirp.RechirpOf.UUID, byID)
+		}
+		if chirp.QuoteOf.Valid {
+			res[i].QuoteOf = chirpEmbed(chirp.QuoteOf.UUID, byID)
+		}
+	}
+	return res, nil
+}
+
+func chirpEmbed(id uuid.UUID, originals map[uuid.UUID]*Chirp) *ChirpEmbed {
+	original, ok := originals[id]
+	return &ChirpEmbed{
+		ID:      id,
+		Deleted: !ok,
+		Chirp:   original,
+	}
+}
+
+func (cfg *apiConfig) renderChirpsWithoutEmbeds(ctx context.Context, chirps []database.Chirp, viewerID uuid.UUID) ([]Chirp, error) {
+	ids := make([]uuid.UUID, len(chirps))
+	for i, chirp := range chirps {
+		ids[i] = chirp.ID
+	}
+
+	replyCounts := make(map[uuid.UUID]int64, len(chirps))
+	likeCounts := make(map[uuid.UUID]int64, len(chirps))
+	likedByViewer := make(map[uuid.UUID]bool)
+	mentions := make(map[uuid.UUID]map[string]uuid.UUID)
+	chirpMedia := make(map[uuid.UUID][]ChirpMedia)
+	polls := make(map[uuid.UUID]*ChirpPoll)
+	linkPreviews := make(map[uuid.UUID]*LinkPreview)
+	if len(ids) > 0 {
+		replyRows, err := cfg.db.CountChirpReplies(ctx, database.CountChirpRepliesParams{
+			ChirpIds: ids,
+			ViewerID: nullViewer(viewerID),
+		})
+		if err != nil {
+			return nil, err
+		}
+		for _, row := range replyRows {
+			replyCounts[row.ReplyTo.UUID] = row.ReplyCount
+		}
+
+		likeRows, err := cfg.db.CountChirpLikes(ctx, database.CountChirpLikesParams{
+			ChirpIds: ids,
+			ViewerID: nullViewer(viewerID),
+		})
+		if err != nil {
+			return nil, err
+		}
+		for _, row := range likeRows {
+			likeCounts[row.ChirpID] = row.LikeCount
+		}
+
+		if viewerID != uuid.Nil {
+			liked, err := cfg.db.GetChirpsLikedByUser(ctx, database.GetChirpsLikedByUserParams{
+				UserID:   viewerID,
+				ChirpIds: ids,
+			})
+			if err != nil {
+				return nil, err
+			}
+			for _, chirpID := range liked {
+				likedByViewer[chirpID] = true
+			}
+		}
+
+		mentionRows, err := cfg.db.GetChirpMentions(ctx, ids)
+		if err != nil {
+			return nil, err
+		}
+		for _, row := range mentionRows {
+			if mentions[row.ChirpID] == nil {
+				mentions[row.ChirpID] = make(map[string]uuid.UUID)
+			}
+			mentions[row.ChirpID][strings.ToLower(row.Email)] = row.UserID
+		}
+
+		mediaRows, err := cfg.db.GetChirpMedia(ctx, ids)
+		if err != nil {
+			return nil, err
+		}
+		for _, row := range mediaRows {
+			chirpMedia[row.ChirpID] = append(chirpMedia[row.ChirpID], ChirpMedia{
+				URL:             cfg.storage.URL(row.StorageKey),
+				ThumbnailURL:    cfg.storage.URL(row.ThumbnailKey),
+				ContentType:     row.ContentType,
+				Width:           row.Width,
+				Height:          row.Height,
+				ThumbnailWidth:  row.ThumbnailWidth,
+				ThumbnailHeight: row.ThumbnailHeight,
+				AltText:         row.AltText,
+			})
+		}
+
+		polls, err = cfg.loadChirpPolls(ctx, ids, viewerID)
+		if err != nil {
+			return nil, err
+		}
+
+		previewRows, err := cfg.db.GetChirpLinkPreviews(ctx, ids)
+		if err != nil {
+			return nil, err
+		}
+		for _, row := range previewRows {
+			linkPreviews[row.ChirpID] = &LinkPreview{
+				URL:         row.Url,
+				Title:       row.Title,
+				Description: row.Description,
+				ImageURL:    row.ImageUrl,
+				SiteName:    row.SiteName,
+			}
+		}
+	}
+
+	res := make([]Chirp, len(chirps))
+	for i, chirp := range chirps {
+		var replyTo *uuid.UUID
+		if chirp.ReplyTo.Valid {
+			replyTo = &chirp.ReplyTo.UUID
+		}
+		var publishAt *time.Time
+		if chirp.PublishAt.Valid {
+			publishAt = &chirp.PublishAt.Time
+		}
+		res[i] = Chirp{
+			ID:          chirp.ID,
+			CreatedAt:   chirp.CreatedAt,
+			UpdatedAt:   chirp.UpdatedAt,
+			Body:        chirp.Body,
+			UserId:      chirp.UserID,
+			ReplyTo:     replyTo,
+			ReplyCount:  replyCounts[chirp.ID],
+			LikeCount:   likeCounts[chirp.ID],
+			LikedByMe:   likedByViewer[chirp.ID],
+			Entities:    renderChirpEntities(chirp.Body, mentions[chirp.ID]),
+			Media:       chirpMedia[chirp.ID],
+			Poll:        polls[chirp.ID],
+			LinkPreview: linkPreviews[chirp.ID],
+			PublishAt:   publishAt,
+		}
+	}
+	return res, nil
+}
+
+// renderChirpEntities extracts the entities of a chirp body, attaching
+// the ID of every mentioned user that was resolved when it was saved.
+func renderChirpEntities(body string, mentions map[string]uuid.UUID) []ChirpEntity {
+	found := entities.Extract(body)
+	res := make([]ChirpEntity, len(found))
+	for i, e := range found {
+		res[i] = ChirpEntity{
+			Kind:   e.Kind,
+			Offset: e.Offset,
+			Length: e.Length,
+			Text:   e.Text,
+		}
+		if userID, ok := mentions[e.Value]; ok && e.Kind == entities.KindMention {
+			res[i].UserID = &userID
+		}
+		if e.Kind == entities.KindURL {
+			res[i].URL = e.Value
+			res[i].DisplayURL = e.Display
+		}
+	}
+	return res
+}
+
+func (cfg *apiConfig) renderChirp(ctx context.Context, chirp database.Chirp, viewerID uuid.UUID) (Chirp, error) {
+	res, err := cfg.renderChirps(ctx, []database.Chirp{chirp}, viewerID)
+	if err != nil {
+		return Chirp{}, err
+	}
+	return res[0], nil
+}
+
+// saveChirpEntities indexes the hashtags and links of a chirp and
+// resolves its mentions to users. Previously saved rows are replaced, so it's safe to
+// call after an edit.
+func saveChirpEntities(ctx context.Context, q *database.Queries, chirp database.Chirp) error {
+	if err := q.DeleteChirpTags(ctx, chirp.ID); err != nil {
+		return err
+	}
+	if err := q.DeleteChirpMentions(ctx, chirp.ID); err != nil {
+		return err
+	}
+	if err := q.DeleteChirpLinks(ctx, chirp.ID); err != nil {
+		return err
+	}
+
+	if tags := entities.Hashtags(chirp.Body); len(tags) > 0 {
+		// Tags date from the chirp, so editing an old chirp doesn't
+		// make its tags trend again.
+		err := q.CreateChirpTags(ctx, database.CreateChirpTagsParams{
+			ChirpID:   chirp.ID,
+			Tags:      tags,
+			CreatedAt: chirp.CreatedAt,
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	if urls := entities.URLs(chirp.Body); len(urls) > 0 {
+		err := q.CreateChirpLinks(ctx, database.CreateChirpLinksParams{
+			ChirpID: chirp.ID,
+			Urls:    urls,
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	emails := entities.Mentions(chirp.Body)
+	if len(emails) == 0 {
+		return nil
+	}
+	users, err := q.GetUsersByEmails(ctx, emails)
+	if err != nil {
+		return err
+	}
+	if len(users) == 0 {
+		return nil
+	}
+	userIDs := make([]uuid.UUID, len(users))
+	for i, user := range users {
+		userIDs[i] = user.ID
+	}
+	return q.CreateChirpMentions(ctx, database.CreateChirpMentionsParams{
+		ChirpID: chirp.ID,
+		UserIds: userIDs,
+	})
+}
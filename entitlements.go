@@ -0,0 +1,29 @@
+package main
+
+import (
+	"context"
+	"errors"
+
+	"github.com/DanilShapilov/chirpy/internal/entitlements"
+	"github.com/google/uuid"
+)
+
+// entitlements looks up what the user's plan allows. Handlers should ask
+// here rather than checking IsChirpyRed themselves.
+func (cfg *apiConfig) entitlements(ctx context.Context, userID uuid.UUID) (entitlements.Entitlements, error) {
+	user, err := cfg.db.GetUser(ctx, userID)
+	if err != nil {
+		return entitlements.Entitlements{}, err
+	}
+	return entitlements.For(user.IsChirpyRed), nil
+}
+
+// limitStatus picks the status code for err, using the one of a plan
+// limit when err is an *entitlements.LimitError.
+func limitStatus(err error, fallback int) int {
+	var limitErr *entitlements.LimitError
+	if errors.As(err, &limitErr) {
+		return limitErr.StatusCode()
+	}
+	return fallback
+}
@@ -0,0 +1,181 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/DanilShapilov/chirpy/internal/database"
+)
+
+type BannedWord struct {
+	Word      string    `json:"word"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// reloadBannedWords rebuilds the profanity filter word list from the
+// words loaded at startup plus the banned_words table.
+func (cfg *apiConfig) reloadBannedWords(ctx context.Context) error {
+	rows, err := cfg.db.GetBannedWords(ctx)
+	if err != nil {
+		return err
+	}
+	words := make([]string, 0, len(cfg.profanityWords)+len(rows))
+	words = append(words, cfg.profanityWords...)
+	for _, row := range rows {
+		words = append(words, row.Word)
+	}
+	cfg.profanity.SetWords(words)
+	return nil
+}
+
+// runBannedWordsReloader reloads the banned words every interval, so
+// words added or removed through another instance are picked up. It
+// blocks until ctx is done.
+func (cfg *apiConfig) runBannedWordsReloader(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		if err := cfg.reloadBannedWords(ctx); err != nil {
+			log.Printf("Couldn't reload banned words: %s", err)
+		}
+	}
+}
+
+func (cfg *apiConfig) handlerBannedWordsList(w http.ResponseWriter, req *http.Request) {
+	if _, ok := cfg.authorizeAdmin(w, req); !ok {
+		return
+	}
+
+	rows, err := cfg.db.GetBannedWords(req.Context())
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't get banned words", err)
+		return
+	}
+
+	res := make([]BannedWord, len(rows))
+	for i, row := range rows {
+		res[i] = BannedWord{
+			Word:      row.Word,
+			CreatedAt: row.CreatedAt,
+		}
+	}
+
+	respondWithJSON(w, http.StatusOK, res)
+}
+
+func (cfg *apiConfig) handlerBannedWordsCreate(w http.ResponseWriter, req *http.Request) {
+	type reqData struct {
+		Word string `json:"word"`
+	}
+
+	moderator, ok := cfg.authorizeAdmin(w, req)
+	if !ok {
+		return
+	}
+
+	decoder := json.NewDecoder(req.Body)
+	params := reqData{}
+	err := decoder.Decode(&params)
+	defer req.Body.Close()
+
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't decode parameters", err)
+		return
+	}
+
+	word := strings.ToLower(strings.TrimSpace(params.Word))
+	if word == "" {
+		respondWithError(w, http.StatusBadRequest, "Word couldn't be empty", nil)
+		return
+	}
+
+	tx, err := cfg.dbConn.BeginTx(req.Context(), nil)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't save banned word", err)
+		return
+	}
+	defer tx.Rollback()
+	qtx := cfg.db.WithTx(tx)
+
+	err = qtx.CreateBannedWord(req.Context(), word)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't save banned word", err)
+		return
+	}
+	err = qtx.CreateModerationAction(req.Context(), database.CreateModerationActionParams{
+		ModeratorID: moderator.ID,
+		Action:      actionBanWord,
+		Reason:      word,
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't record moderation action", err)
+		return
+	}
+	if err := tx.Commit(); err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't save banned word", err)
+		return
+	}
+	if err := cfg.reloadBannedWords(req.Context()); err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't reload banned words", err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (cfg *apiConfig) handlerBannedWordsDelete(w http.ResponseWriter, req *http.Request) {
+	moderator, ok := cfg.authorizeAdmin(w, req)
+	if !ok {
+		return
+	}
+
+	word := strings.ToLower(req.PathValue("word"))
+
+	tx, err := cfg.dbConn.BeginTx(req.Context(), nil)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't delete banned word", err)
+		return
+	}
+	defer tx.Rollback()
+	qtx := cfg.db.WithTx(tx)
+
+	deleted, err := qtx.DeleteBannedWord(req.Context(), word)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't delete banned word", err)
+		return
+	}
+	if deleted == 0 {
+		respondWithError(w, http.StatusNotFound, "Couldn't find banned word", nil)
+		return
+	}
+	err = qtx.CreateModerationAction(req.Context(), database.CreateModerationActionParams{
+		ModeratorID: moderator.ID,
+		Action:      actionUnbanWord,
+		Reason:      word,
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't record moderation action", err)
+		return
+	}
+	if err := tx.Commit(); err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't delete banned word", err)
+		return
+	}
+	if err := cfg.reloadBannedWords(req.Context()); err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't reload banned words", err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
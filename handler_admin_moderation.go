@@ -0,0 +1,688 @@
+package main
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/DanilShapilov/chirpy/internal/database"
+	"github.com/google/uuid"
+)
+
+// Actions recorded in the moderation audit log.
+const (
+	actionDismissReport = "dismiss_report"
+	actionHideChirp     = "hide_chirp"
+	actionRestoreChirp  = "restore_chirp"
+	actionSuspendUser   = "suspend_user"
+	actionUnsuspendUser = "unsuspend_user"
+	actionBanWord       = "ban_word"
+	actionUnbanWord     = "unban_word"
+	actionAcceptAppeal  = "accept_appeal"
+	actionRejectAppeal  = "reject_appeal"
+)
+
+type ReportWithChirp struct {
+	Report
+	ChirpBody   string    `json:"chirp_body"`
+	ChirpUserID uuid.UUID `json:"chirp_user_id"`
+}
+
+type ReportsPage struct {
+	Reports    []ReportWithChirp `json:"reports"`
+	NextCursor *string           `json:"next_cursor"`
+}
+
+type ModerationAction struct {
+	ID          uuid.UUID  `json:"id"`
+	CreatedAt   time.Time  `json:"created_at"`
+	ModeratorID uuid.UUID  `json:"moderator_id"`
+	Action      string     `json:"action"`
+	ChirpID     *uuid.UUID `json:"chirp_id"`
+	UserID      *uuid.UUID `json:"user_id"`
+	ReportID    *uuid.UUID `json:"report_id"`
+	Reason      string     `json:"reason"`
+}
+
+type AppealsPage struct {
+	Appeals    []ModerationDecision `json:"appeals"`
+	NextCursor *string              `json:"next_cursor"`
+}
+
+type ModerationActionsPage struct {
+	Actions    []ModerationAction `json:"actions"`
+	NextCursor *string            `json:"next_cursor"`
+}
+
+// decodeModerationReason reads the optional {"reason": ...} body sent
+// with a moderator action. An empty body means no reason.
+func decodeModerationReason(req *http.Request) (string, error) {
+	type reqData struct {
+		Reason string `json:"reason"`
+	}
+	defer req.Body.Close()
+	params := reqData{}
+	err := json.NewDecoder(req.Body).Decode(&params)
+	if err != nil && !errors.Is(err, io.EOF) {
+		return "", err
+	}
+	return params.Reason, nil
+}
+
+func (cfg *apiConfig) handlerAdminReportsList(w http.ResponseWriter, req *http.Request) {
+	if _, ok := cfg.authorizeAdmin(w, req); !ok {
+		return
+	}
+
+	page, err := parsePageParams(req.URL.Query())
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, err.Error(), err)
+		return
+	}
+	if !page.Paginated {
+		page.Limit = defaultPageLimit
+	}
+
+	status := req.URL.Query().Get("status")
+	if status == "" {
+		status = "open"
+	}
+	params := database.ListReportsParams{
+		Status: status,
+		// Fetch one extra row to find out whether there is a next page.
+		Limit: page.Limit + 1,
+	}
+	if page.Cursor != nil {
+		params.CursorCreatedAt = sql.NullTime{Time: page.Cursor.CreatedAt, Valid: true}
+		params.CursorID = uuid.NullUUID{UUID: page.Cursor.ID, Valid: true}
+	}
+
+	rows, err := cfg.db.ListReports(req.Context(), params)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't get reports", err)
+		return
+	}
+
+	var nextCursor *string
+	if len(rows) > int(page.Limit) {
+		rows = rows[:page.Limit]
+		last := rows[len(rows)-1]
+		cursor := encodeCursor(last.CreatedAt, last.ID)
+		nextCursor = &cursor
+	}
+
+	reports := make([]ReportWithChirp, len(rows))
+	for i, row := range rows {
+		reports[i] = ReportWithChirp{
+			Report: renderReport(database.Report{
+				ID:         row.ID,
+				CreatedAt:  row.CreatedAt,
+				ChirpID:    row.ChirpID,
+				ReporterID: row.ReporterID,
+				Reason:     row.Reason,
+				Details:    row.Details,
+				Status:     row.Status,
+				ResolvedAt: row.ResolvedAt,
+				ResolvedBy: row.ResolvedBy,
+			}),
+			ChirpBody:   row.ChirpBody,
+			ChirpUserID: row.ChirpUserID,
+		}
+	}
+
+	respondWithJSON(w, http.StatusOK, ReportsPage{
+		Reports:    reports,
+		NextCursor: nextCursor,
+	})
+}
+
+func (cfg *apiConfig) handlerAdminReportsDismiss(w http.ResponseWriter, req *http.Request) {
+	reportIDString := req.PathValue("reportID")
+	reportID, err := uuid.Parse(reportIDString)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid report ID", err)
+		return
+	}
+
+	moderator, ok := cfg.authorizeAdmin(w, req)
+	if !ok {
+		return
+	}
+
+	reason, err := decodeModerationReason(req)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't decode parameters", err)
+		return
+	}
+
+	tx, err := cfg.dbConn.BeginTx(req.Context(), nil)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't dismiss report", err)
+		return
+	}
+	defer tx.Rollback()
+	qtx := cfg.db.WithTx(tx)
+
+	report, err := qtx.DismissReport(req.Context(), database.DismissReportParams{
+		ResolvedBy: uuid.NullUUID{UUID: moderator.ID, Valid: true},
+		ID:         reportID,
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			respondWithError(w, http.StatusNotFound, "Couldn't find open report", err)
+			return
+		}
+		respondWithError(w, http.StatusInternalServerError, "Couldn't dismiss report", err)
+		return
+	}
+
+	err = qtx.CreateModerationAction(req.Context(), database.CreateModerationActionParams{
+		ModeratorID: moderator.ID,
+		Action:      actionDismissReport,
+		ChirpID:     uuid.NullUUID{UUID: report.ChirpID, Valid: true},
+		ReportID:    uuid.NullUUID{UUID: report.ID, Valid: true},
+		Reason:      reason,
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't record moderation action", err)
+		return
+	}
+
+	if err := tx.Commit(); err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't dismiss report", err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, renderReport(report))
+}
+
+// handlerAdminChirpsHide takes a chirp down and resolves its open
+// reports. The author can't restore a hidden chirp.
+func (cfg *apiConfig) handlerAdminChirpsHide(w http.ResponseWriter, req *http.Request) {
+	chirpIDString := req.PathValue("chirpID")
+	chirpID, err := uuid.Parse(chirpIDString)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid chirp ID", err)
+		return
+	}
+
+	moderator, ok := cfg.authorizeAdmin(w, req)
+	if !ok {
+		return
+	}
+
+	reason, err := decodeModerationReason(req)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't decode parameters", err)
+		return
+	}
+
+	tx, err := cfg.dbConn.BeginTx(req.Context(), nil)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't hide chirp", err)
+		return
+	}
+	defer tx.Rollback()
+	qtx := cfg.db.WithTx(tx)
+
+	// Chirps their author deleted can be hidden too, otherwise the
+	// author could restore them once the report is dealt with.
+	chirp, err := qtx.HideChirp(req.Context(), chirpID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			respondWithError(w, http.StatusNotFound, "Couldn't find chirp", err)
+			return
+		}
+		respondWithError(w, http.StatusInternalServerError, "Couldn't hide chirp", err)
+		return
+	}
+
+	_, err = qtx.ResolveChirpReports(req.Context(), database.ResolveChirpReportsParams{
+		ResolvedBy: uuid.NullUUID{UUID: moderator.ID, Valid: true},
+		ChirpID:    chirp.ID,
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't resolve reports", err)
+		return
+	}
+
+	err = qtx.CreateModerationAction(req.Context(), database.CreateModerationActionParams{
+		ModeratorID: moderator.ID,
+		Action:      actionHideChirp,
+		ChirpID:     uuid.NullUUID{UUID: chirp.ID, Valid: true},
+		UserID:      uuid.NullUUID{UUID: chirp.UserID, Valid: true},
+		Reason:      reason,
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't record moderation action", err)
+		return
+	}
+
+	if err := tx.Commit(); err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't hide chirp", err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (cfg *apiConfig) handlerAdminChirpsRestore(w http.ResponseWriter, req *http.Request) {
+	chirpIDString := req.PathValue("chirpID")
+	chirpID, err := uuid.Parse(chirpIDString)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid chirp ID", err)
+		return
+	}
+
+	moderator, ok := cfg.authorizeAdmin(w, req)
+	if !ok {
+		return
+	}
+
+	reason, err := decodeModerationReason(req)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't decode parameters", err)
+		return
+	}
+
+	tx, err := cfg.dbConn.BeginTx(req.Context(), nil)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't restore chirp", err)
+		return
+	}
+	defer tx.Rollback()
+	qtx := cfg.db.WithTx(tx)
+
+	// A chirp its author had deleted before it was hidden stays deleted.
+	chirp, err := qtx.UnhideChirp(req.Context(), chirpID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			respondWithError(w, http.StatusNotFound, "Couldn't find hidden chirp", err)
+			return
+		}
+		// The author rechirped the same chirp again while this one was
+		// hidden.
+		if isUniqueViolation(err) {
+			respondWithError(w, http.StatusConflict, "Chirp is already rechirped", err)
+			return
+		}
+		respondWithError(w, http.StatusInternalServerError, "Couldn't restore chirp", err)
+		return
+	}
+
+	err = qtx.CreateModerationAction(req.Context(), database.CreateModerationActionParams{
+		ModeratorID: moderator.ID,
+		Action:      actionRestoreChirp,
+		ChirpID:     uuid.NullUUID{UUID: chirp.ID, Valid: true},
+		UserID:      uuid.NullUUID{UUID: chirp.UserID, Valid: true},
+		Reason:      reason,
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't record moderation action", err)
+		return
+	}
+
+	if err := tx.Commit(); err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't restore chirp", err)
+		return
+	}
+
+	jsonKeysChirp, err := cfg.renderChirp(req.Context(), chirp, moderator.ID)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't render chirp", err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, jsonKeysChirp)
+}
+
+// handlerAdminSuspensionCreate suspends a user until the given time, or
+// until the suspension is lifted when no time is given.
+func (cfg *apiConfig) handlerAdminSuspensionCreate(w http.ResponseWriter, req *http.Request) {
+	type reqData struct {
+		Reason string     `json:"reason"`
+		Until  *time.Time `json:"until"`
+	}
+
+	userIDString := req.PathValue("userID")
+	userID, err := uuid.Parse(userIDString)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid user ID", err)
+		return
+	}
+
+	moderator, ok := cfg.authorizeAdmin(w, req)
+	if !ok {
+		return
+	}
+
+	decoder := json.NewDecoder(req.Body)
+	params := reqData{}
+	err = decoder.Decode(&params)
+	defer req.Body.Close()
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't decode parameters", err)
+		return
+	}
+
+	if userID == moderator.ID {
+		respondWithError(w, http.StatusBadRequest, "Couldn't suspend yourself", nil)
+		return
+	}
+	var until sql.NullTime
+	if params.Until != nil {
+		if !params.Until.After(time.Now()) {
+			respondWithError(w, http.StatusBadRequest, "until must be in the future", nil)
+			return
+		}
+		until = sql.NullTime{Time: params.Until.UTC(), Valid: true}
+	}
+
+	tx, err := cfg.dbConn.BeginTx(req.Context(), nil)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't suspend user", err)
+		return
+	}
+	defer tx.Rollback()
+	qtx := cfg.db.WithTx(tx)
+
+	user, err := qtx.SuspendUser(req.Context(), database.SuspendUserParams{
+		SuspendedUntil:   until,
+		SuspensionReason: params.Reason,
+		ID:               userID,
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			respondWithError(w, http.StatusNotFound, "Couldn't find user", err)
+			return
+		}
+		respondWithError(w, http.StatusInternalServerError, "Couldn't suspend user", err)
+		return
+	}
+
+	// Suspended users can't sign in or refresh, and the access tokens
+	// they already hold stop working as well.
+	if err := qtx.SetUserSessionsNotBefore(req.Context(), sessionsNotBeforeParams(user.ID)); err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't revoke access tokens", err)
+		return
+	}
+
+	err = qtx.CreateModerationAction(req.Context(), database.CreateModerationActionParams{
+		ModeratorID: moderator.ID,
+		Action:      actionSuspendUser,
+		UserID:      uuid.NullUUID{UUID: user.ID, Valid: true},
+		Reason:      params.Reason,
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't record moderation action", err)
+		return
+	}
+
+	if err := tx.Commit(); err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't suspend user", err)
+		return
+	}
+	cfg.accessTokens.forgetUser(user.ID)
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (cfg *apiConfig) handlerAdminSuspensionDelete(w http.ResponseWriter, req *http.Request) {
+	userIDString := req.PathValue("userID")
+	userID, err := uuid.Parse(userIDString)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid user ID", err)
+		return
+	}
+
+	moderator, ok := cfg.authorizeAdmin(w, req)
+	if !ok {
+		return
+	}
+
+	reason, err := decodeModerationReason(req)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't decode parameters", err)
+		return
+	}
+
+	tx, err := cfg.dbConn.BeginTx(req.Context(), nil)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't lift suspension", err)
+		return
+	}
+	defer tx.Rollback()
+	qtx := cfg.db.WithTx(tx)
+
+	user, err := qtx.UnsuspendUser(req.Context(), userID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			respondWithError(w, http.StatusNotFound, "Couldn't find suspended user", err)
+			return
+		}
+		respondWithError(w, http.StatusInternalServerError, "Couldn't lift suspension", err)
+		return
+	}
+
+	err = qtx.CreateModerationAction(req.Context(), database.CreateModerationActionParams{
+		ModeratorID: moderator.ID,
+		Action:      actionUnsuspendUser,
+		UserID:      uuid.NullUUID{UUID: user.ID, Valid: true},
+		Reason:      reason,
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't record moderation action", err)
+		return
+	}
+
+	if err := tx.Commit(); err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't lift suspension", err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (cfg *apiConfig) handlerAdminAuditLog(w http.ResponseWriter, req *http.Request) {
+	if _, ok := cfg.authorizeAdmin(w, req); !ok {
+		return
+	}
+
+	page, err := parsePageParams(req.URL.Query())
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, err.Error(), err)
+		return
+	}
+	if !page.Paginated {
+		page.Limit = defaultPageLimit
+	}
+
+	params := database.ListModerationActionsParams{
+		// Fetch one extra row to find out whether there is a next page.
+		Limit: page.Limit + 1,
+	}
+	if page.Cursor != nil {
+		params.CursorCreatedAt = sql.NullTime{Time: page.Cursor.CreatedAt, Valid: true}
+		params.CursorID = uuid.NullUUID{UUID: page.Cursor.ID, Valid: true}
+	}
+
+	rows, err := cfg.db.ListModerationActions(req.Context(), params)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't get audit log", err)
+		return
+	}
+
+	var nextCursor *string
+	if len(rows) > int(page.Limit) {
+		rows = rows[:page.Limit]
+		last := rows[len(rows)-1]
+		cursor := encodeCursor(last.CreatedAt, last.ID)
+		nextCursor = &cursor
+	}
+
+	actions := make([]ModerationAction, len(rows))
+	for i, row := range rows {
+		actions[i] = ModerationAction{
+			ID:          row.ID,
+			CreatedAt:   row.CreatedAt,
+			ModeratorID: row.ModeratorID,
+			Action:      row.Action,
+			Reason:      row.Reason,
+		}
+		if row.ChirpID.Valid {
+			actions[i].ChirpID = &row.ChirpID.UUID
+		}
+		if row.UserID.Valid {
+			actions[i].UserID = &row.UserID.UUID
+		}
+		if row.ReportID.Valid {
+			actions[i].ReportID = &row.ReportID.UUID
+		}
+	}
+
+	respondWithJSON(w, http.StatusOK, ModerationActionsPage{
+		Actions:    actions,
+		NextCursor: nextCursor,
+	})
+}
+
+// handlerAdminAppealsList lists the pending appeals, oldest first.
+func (cfg *apiConfig) handlerAdminAppealsList(w http.ResponseWriter, req *http.Request) {
+	if _, ok := cfg.authorizeAdmin(w, req); !ok {
+		return
+	}
+
+	page, err := parsePageParams(req.URL.Query())
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, err.Error(), err)
+		return
+	}
+	if !page.Paginated {
+		page.Limit = defaultPageLimit
+	}
+
+	params := database.ListPendingAppealsParams{
+		// Fetch one extra row to find out whether there is a next page.
+		Limit: page.Limit + 1,
+	}
+	if page.Cursor != nil {
+		params.CursorAppealedAt = sql.NullTime{Time: page.Cursor.CreatedAt, Valid: true}
+		params.CursorID = uuid.NullUUID{UUID: page.Cursor.ID, Valid: true}
+	}
+
+	rows, err := cfg.db.ListPendingAppeals(req.Context(), params)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't get appeals", err)
+		return
+	}
+
+	var nextCursor *string
+	if len(rows) > int(page.Limit) {
+		rows = rows[:page.Limit]
+		last := rows[len(rows)-1]
+		cursor := encodeCursor(last.AppealedAt.Time, last.ID)
+		nextCursor = &cursor
+	}
+
+	appeals := make([]ModerationDecision, len(rows))
+	for i, row := range rows {
+		appeals[i] = renderModerationDecision(row)
+	}
+
+	respondWithJSON(w, http.StatusOK, AppealsPage{
+		Appeals:    appeals,
+		NextCursor: nextCursor,
+	})
+}
+
+// handlerAdminAppealsAccept overturns an automatic decision and brings
+// the chirp back.
+func (cfg *apiConfig) handlerAdminAppealsAccept(w http.ResponseWriter, req *http.Request) {
+	cfg.resolveAppeal(w, req, true)
+}
+
+func (cfg *apiConfig) handlerAdminAppealsReject(w http.ResponseWriter, req *http.Request) {
+	cfg.resolveAppeal(w, req, false)
+}
+
+func (cfg *apiConfig) resolveAppeal(w http.ResponseWriter, req *http.Request, accept bool) {
+	decisionIDString := req.PathValue("decisionID")
+	decisionID, err := uuid.Parse(decisionIDString)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid decision ID", err)
+		return
+	}
+
+	moderator, ok := cfg.authorizeAdmin(w, req)
+	if !ok {
+		return
+	}
+
+	reason, err := decodeModerationReason(req)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't decode parameters", err)
+		return
+	}
+
+	status, action := "rejected", actionRejectAppeal
+	if accept {
+		status, action = "accepted", actionAcceptAppeal
+	}
+
+	tx, err := cfg.dbConn.BeginTx(req.Context(), nil)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't resolve appeal", err)
+		return
+	}
+	defer tx.Rollback()
+	qtx := cfg.db.WithTx(tx)
+
+	decision, err := qtx.ResolveAppeal(req.Context(), database.ResolveAppealParams{
+		AppealStatus: status,
+		ResolvedBy:   uuid.NullUUID{UUID: moderator.ID, Valid: true},
+		ID:           decisionID,
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			respondWithError(w, http.StatusNotFound, "Couldn't find pending appeal", err)
+			return
+		}
+		respondWithError(w, http.StatusInternalServerError, "Couldn't resolve appeal", err)
+		return
+	}
+
+	if accept {
+		// The chirp may already have been restored by a moderator.
+		_, err := qtx.UnhideChirp(req.Context(), decision.ChirpID)
+		if err != nil && !errors.Is(err, sql.ErrNoRows) {
+			if isUniqueViolation(err) {
+				respondWithError(w, http.StatusConflict, "Chirp is already rechirped", err)
+				return
+			}
+			respondWithError(w, http.StatusInternalServerError, "Couldn't restore chirp", err)
+			return
+		}
+	}
+
+	err = qtx.CreateModerationAction(req.Context(), database.CreateModerationActionParams{
+		ModeratorID: moderator.ID,
+		Action:      action,
+		ChirpID:     uuid.NullUUID{UUID: decision.ChirpID, Valid: true},
+		UserID:      uuid.NullUUID{UUID: decision.UserID, Valid: true},
+		Reason:      reason,
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't record moderation action", err)
+		return
+	}
+
+	if err := tx.Commit(); err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't resolve appeal", err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, renderModerationDecision(decision))
+}
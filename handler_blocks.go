@@ -0,0 +1,157 @@
+package main
+
+import (
+	"net/http"
+
+	"github.com/DanilShapilov/chirpy/internal/database"
+	"github.com/google/uuid"
+)
+
+// handlerBlockCreate blocks a user. Follows between the two users are
+// removed, and the queries keep them from seeing or interacting with
+// each other's chirps from then on.
+func (cfg *apiConfig) handlerBlockCreate(w http.ResponseWriter, req *http.Request) {
+	blockedIDString := req.PathValue("userID")
+	blockedID, err := uuid.Parse(blockedIDString)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid user ID", err)
+		return
+	}
+
+	userID, ok := cfg.authenticate(w, req)
+	if !ok {
+		return
+	}
+
+	if blockedID == userID {
+		respondWithError(w, http.StatusBadRequest, "Couldn't block yourself", nil)
+		return
+	}
+
+	blocked, err := cfg.db.GetUser(req.Context(), blockedID)
+	if err != nil {
+		respondWithError(w, http.StatusNotFound, "Couldn't find user", err)
+		return
+	}
+
+	tx, err := cfg.dbConn.BeginTx(req.Context(), nil)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't block user", err)
+		return
+	}
+	defer tx.Rollback()
+	qtx := cfg.db.WithTx(tx)
+
+	err = qtx.BlockUser(req.Context(), database.BlockUserParams{
+		BlockerID: userID,
+		BlockedID: blocked.ID,
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't block user", err)
+		return
+	}
+
+	err = qtx.DeleteFollowsBetween(req.Context(), database.DeleteFollowsBetweenParams{
+		UserID:      userID,
+		OtherUserID: blocked.ID,
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't remove follows", err)
+		return
+	}
+
+	if err := tx.Commit(); err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't block user", err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (cfg *apiConfig) handlerBlockDelete(w http.ResponseWriter, req *http.Request) {
+	blockedIDString := req.PathValue("userID")
+	blockedID, err := uuid.Parse(blockedIDString)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid user ID", err)
+		return
+	}
+
+	userID, ok := cfg.authenticate(w, req)
+	if !ok {
+		return
+	}
+
+	_, err = cfg.db.UnblockUser(req.Context(), database.UnblockUserParams{
+		BlockerID: userID,
+		BlockedID: blockedID,
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't unblock user", err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// handlerMuteCreate mutes a user, hiding their chirps from the caller's
+// feeds. Unlike a block, the muted user isn't affected in any way.
+func (cfg *apiConfig) handlerMuteCreate(w http.ResponseWriter, req *http.Request) {
+	mutedIDString := req.PathValue("userID")
+	mutedID, err := uuid.Parse(mutedIDString)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid user ID", err)
+		return
+	}
+
+	userID, ok := cfg.authenticate(w, req)
+	if !ok {
+		return
+	}
+
+	if mutedID == userID {
+		respondWithError(w, http.StatusBadRequest, "Couldn't mute yourself", nil)
+		return
+	}
+
+	muted, err := cfg.db.GetUser(req.Context(), mutedID)
+	if err != nil {
+		respondWithError(w, http.StatusNotFound, "Couldn't find user", err)
+		return
+	}
+
+	err = cfg.db.MuteUser(req.Context(), database.MuteUserParams{
+		MuterID: userID,
+		MutedID: muted.ID,
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't mute user", err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (cfg *apiConfig) handlerMuteDelete(w http.ResponseWriter, req *http.Request) {
+	mutedIDString := req.PathValue("userID")
+	mutedID, err := uuid.Parse(mutedIDString)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid user ID", err)
+		return
+	}
+
+	userID, ok := cfg.authenticate(w, req)
+	if !ok {
+		return
+	}
+
+	_, err = cfg.db.UnmuteUser(req.Context(), database.UnmuteUserParams{
+		MuterID: userID,
+		MutedID: mutedID,
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't unmute user", err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,194 @@
+package main
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/DanilShapilov/chirpy/internal/database"
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+const maxCollectionNameLength = 50
+
+type BookmarkCollection struct {
+	ID        uuid.UUID `json:"id"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+	Name      string    `json:"name"`
+}
+
+func (cfg *apiConfig) handlerCollectionsCreate(w http.ResponseWriter, req *http.Request) {
+	type reqData struct {
+		Name string `json:"name"`
+	}
+
+	userID, ok := cfg.authenticate(w, req)
+	if !ok {
+		return
+	}
+
+	defer req.Body.Close()
+	decoder := json.NewDecoder(req.Body)
+	params := reqData{}
+	err := decoder.Decode(&params)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't decode parameters", err)
+		return
+	}
+
+	name, err := validateCollectionName(params.Name)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, err.Error(), err)
+		return
+	}
+
+	collection, err := cfg.db.CreateBookmarkCollection(req.Context(), database.CreateBookmarkCollectionParams{
+		UserID: userID,
+		Name:   name,
+	})
+	if err != nil {
+		if isUniqueViolation(err) {
+			respondWithError(w, http.StatusConflict, "Collection already exists", err)
+			return
+		}
+		respondWithError(w, http.StatusInternalServerError, "Couldn't create collection", err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusCreated, renderBookmarkCollection(collection))
+}
+
+func (cfg *apiConfig) handlerCollectionsList(w http.ResponseWriter, req *http.Request) {
+	userID, ok := cfg.authenticate(w, req)
+	if !ok {
+		return
+	}
+
+	dbCollections, err := cfg.db.ListBookmarkCollections(req.Context(), userID)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't get collections", err)
+		return
+	}
+
+	collections := make([]BookmarkCollection, len(dbCollections))
+	for i, collection := range dbCollections {
+		collections[i] = renderBookmarkCollection(collection)
+	}
+
+	respondWithJSON(w, http.StatusOK, collections)
+}
+
+func (cfg *apiConfig) handlerCollectionsUpdate(w http.ResponseWriter, req *http.Request) {
+	type reqData struct {
+		Name string `json:"name"`
+	}
+
+	collectionIDString := req.PathValue("collectionID")
+	collectionID, err := uuid.Parse(collectionIDString)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid collection ID", err)
+		return
+	}
+
+	userID, ok := cfg.authenticate(w, req)
+	if !ok {
+		return
+	}
+
+	defer req.Body.Close()
+	decoder := json.NewDecoder(req.Body)
+	params := reqData{}
+	err = decoder.Decode(&params)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't decode parameters", err)
+		return
+	}
+
+	name, err := validateCollectionName(params.Name)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, err.Error(), err)
+		return
+	}
+
+	collection, err := cfg.db.RenameBookmarkCollection(req.Context(), database.RenameBookmarkCollectionParams{
+		Name:   name,
+		ID:     collectionID,
+		UserID: userID,
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			respondWithError(w, http.StatusNotFound, "Couldn't find collection", err)
+			return
+		}
+		if isUniqueViolation(err) {
+			respondWithError(w, http.StatusConflict, "Collection already exists", err)
+			return
+		}
+		respondWithError(w, http.StatusInternalServerError, "Couldn't update collection", err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, renderBookmarkCollection(collection))
+}
+
+// handlerCollectionsDelete removes a collection. Its bookmarks are kept
+// and end up outside of any collection.
+func (cfg *apiConfig) handlerCollectionsDelete(w http.ResponseWriter, req *http.Request) {
+	collectionIDString := req.PathValue("collectionID")
+	collectionID, err := uuid.Parse(collectionIDString)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid collection ID", err)
+		return
+	}
+
+	userID, ok := cfg.authenticate(w, req)
+	if !ok {
+		return
+	}
+
+	deleted, err := cfg.db.DeleteBookmarkCollection(req.Context(), database.DeleteBookmarkCollectionParams{
+		ID:     collectionID,
+		UserID: userID,
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't delete collection", err)
+		return
+	}
+	if deleted == 0 {
+		respondWithError(w, http.StatusNotFound, "Couldn't find collection", nil)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func validateCollectionName(name string) (string, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return "", errors.New("Collection name is required")
+	}
+	if utf8.RuneCountInString(name) > maxCollectionNameLength {
+		return "", errors.New("Collection name is too long")
+	}
+	return name, nil
+}
+
+func renderBookmarkCollection(collection database.BookmarkCollection) BookmarkCollection {
+	return BookmarkCollection{
+		ID:        collection.ID,
+		CreatedAt: collection.CreatedAt,
+		UpdatedAt: collection.UpdatedAt,
+		Name:      collection.Name,
+	}
+}
+
+func isUniqueViolation(err error) bool {
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) && pqErr.Code == "23505"
+}
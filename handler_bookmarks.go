@@ -0,0 +1,177 @@
+package main
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/DanilShapilov/chirpy/internal/database"
+	"github.com/google/uuid"
+)
+
+// handlerBookmarksCreate bookmarks a chirp, optionally into one of the
+// user's collections. Bookmarking it again moves it to the given
+// collection, out of its collection when `collection_id` is null, and
+// leaves it where it is when `collection_id` is left out.
+func (cfg *apiConfig) handlerBookmarksCreate(w http.ResponseWriter, req *http.Request) {
+	type reqData struct {
+		// CollectionID is nil when the field is left out and "null" when
+		// it is null.
+		CollectionID json.RawMessage `json:"collection_id"`
+	}
+
+	chirpIDString := req.PathValue("chirpID")
+	chirpID, err := uuid.Parse(chirpIDString)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid chirp ID", err)
+		return
+	}
+
+	userID, ok := cfg.authenticate(w, req)
+	if !ok {
+		return
+	}
+
+	// The body is optional, an empty one bookmarks without a collection.
+	defer req.Body.Close()
+	decoder := json.NewDecoder(req.Body)
+	params := reqData{}
+	err = decoder.Decode(&params)
+	if err != nil && !errors.Is(err, io.EOF) {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't decode parameters", err)
+		return
+	}
+
+	chirp, err := cfg.db.GetChirp(req.Context(), chirpID)
+	if err != nil {
+		respondWithError(w, http.StatusNotFound, "Couldn't find chirp", err)
+		return
+	}
+
+	var requestedID *uuid.UUID
+	if params.CollectionID != nil {
+		if err := json.Unmarshal(params.CollectionID, &requestedID); err != nil {
+			respondWithError(w, http.StatusBadRequest, "Invalid collection ID", err)
+			return
+		}
+	}
+
+	var collectionID uuid.NullUUID
+	if requestedID != nil {
+		collection, err := cfg.db.GetBookmarkCollection(req.Context(), database.GetBookmarkCollectionParams{
+			ID:     *requestedID,
+			UserID: userID,
+		})
+		if err != nil {
+			respondWithError(w, http.StatusNotFound, "Couldn't find collection", err)
+			return
+		}
+		collectionID = uuid.NullUUID{UUID: collection.ID, Valid: true}
+	}
+
+	err = cfg.db.BookmarkChirp(req.Context(), database.BookmarkChirpParams{
+		UserID:         userID,
+		ChirpID:        chirp.ID,
+		CollectionID:   collectionID,
+		KeepCollection: params.CollectionID == nil,
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't bookmark chirp", err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (cfg *apiConfig) handlerBookmarksDelete(w http.ResponseWriter, req *http.Request) {
+	chirpIDString := req.PathValue("chirpID")
+	chirpID, err := uuid.Parse(chirpIDString)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid chirp ID", err)
+		return
+	}
+
+	userID, ok := cfg.authenticate(w, req)
+	if !ok {
+		return
+	}
+
+	_, err = cfg.db.DeleteBookmark(req.Context(), database.DeleteBookmarkParams{
+		UserID:  userID,
+		ChirpID: chirpID,
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't delete bookmark", err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// handlerBookmarksList pages through the user's bookmarks, most recently
+// bookmarked first. The cursor points at a bookmark, not at a chirp, and
+// `collection_id` narrows the list to one collection.
+func (cfg *apiConfig) handlerBookmarksList(w http.ResponseWriter, req *http.Request) {
+	userID, ok := cfg.authenticate(w, req)
+	if !ok {
+		return
+	}
+
+	page, err := parsePageParams(req.URL.Query())
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, err.Error(), err)
+		return
+	}
+	if !page.Paginated {
+		page.Limit = defaultPageLimit
+	}
+
+	params := database.GetBookmarkedChirpsParams{
+		UserID: userID,
+		// Fetch one extra row to find out whether there is a next page.
+		Limit: page.Limit + 1,
+	}
+	if collectionIDString := req.URL.Query().Get("collection_id"); collectionIDString != "" {
+		collectionID, err := uuid.Parse(collectionIDString)
+		if err != nil {
+			respondWithError(w, http.StatusBadRequest, "Invalid collection ID", err)
+			return
+		}
+		params.CollectionID = uuid.NullUUID{UUID: collectionID, Valid: true}
+	}
+	if page.Cursor != nil {
+		params.CursorCreatedAt = sql.NullTime{Time: page.Cursor.CreatedAt, Valid: true}
+		params.CursorID = uuid.NullUUID{UUID: page.Cursor.ID, Valid: true}
+	}
+
+	rows, err := cfg.db.GetBookmarkedChirps(req.Context(), params)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't get bookmarks", err)
+		return
+	}
+
+	var nextCursor *string
+	if len(rows) > int(page.Limit) {
+		rows = rows[:page.Limit]
+		last := rows[len(rows)-1]
+		cursor := encodeCursor(last.BookmarkedAt, last.Chirp.ID)
+		nextCursor = &cursor
+	}
+
+	chirps := make([]database.Chirp, len(rows))
+	for i, row := range rows {
+		chirps[i] = row.Chirp
+	}
+	res, err := cfg.renderChirps(req.Context(), chirps, userID)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't render chirps", err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, ChirpsPage{
+		Chirps:     res,
+		NextCursor: nextCursor,
+	})
+}
@@ -1,109 +1,248 @@
 package main
 
 import (
+	"database/sql"
 	"encoding/json"
+	"errors"
 	"net/http"
-	"strings"
 	"time"
 
-	"errors"
-
-	"github.com/DanilShapilov/chirpy/internal/auth"
 	"github.com/DanilShapilov/chirpy/internal/database"
+	"github.com/DanilShapilov/chirpy/internal/entities"
+	"github.com/DanilShapilov/chirpy/internal/entitlements"
 	"github.com/google/uuid"
 )
 
 type Chirp struct {
-	ID        uuid.UUID `json:"id"`
-	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
-	Body      string    `json:"body"`
-	UserId    uuid.UUID `json:"user_id"`
+	ID          uuid.UUID     `json:"id"`
+	CreatedAt   time.Time     `json:"created_at"`
+	UpdatedAt   time.Time     `json:"updated_at"`
+	Body        string        `json:"body"`
+	UserId      uuid.UUID     `json:"user_id"`
+	ReplyTo     *uuid.UUID    `json:"reply_to"`
+	RechirpOf   *ChirpEmbed   `json:"rechirp_of,omitempty"`
+	QuoteOf     *ChirpEmbed   `json:"quote_of,omitempty"`
+	ReplyCount  int64         `json:"reply_count"`
+	LikeCount   int64         `json:"like_count"`
+	LikedByMe   bool          `json:"liked_by_me"`
+	Entities    []ChirpEntity `json:"entities"`
+	Media       []ChirpMedia  `json:"media"`
+	Poll        *ChirpPoll    `json:"poll,omitempty"`
+	LinkPreview *LinkPreview  `json:"link_preview,omitempty"`
+	PublishAt   *time.Time    `json:"publish_at,omitempty"`
+}
+
+// chirpParams is the body of POST /api/chirps, either decoded from JSON
+// or read from a multipart form.
+type chirpParams struct {
+	Body      string      `json:"body"`
+	ReplyTo   *uuid.UUID  `json:"reply_to"`
+	QuoteOf   *uuid.UUID  `json:"quote_of"`
+	PublishAt *time.Time  `json:"publish_at"`
+	Poll      *pollParams `json:"poll"`
 }
 
 func (cfg *apiConfig) handlerChirpsCreate(w http.ResponseWriter, req *http.Request) {
-	type reqData struct {
-		Body string `json:"body"`
+	type response struct {
+		Chirp
+		MaskedWords []string            `json:"masked_words"`
+		Moderation  *ModerationDecision `json:"moderation,omitempty"`
 	}
 
-	token, err := auth.GetBearerToken(req.Header)
-	if err != nil {
-		respondWithError(
-			w,
-			http.StatusUnauthorized,
-			"Couldn't find JWT",
-			err,
-		)
+	userID, ok := cfg.authenticate(w, req)
+	if !ok {
 		return
 	}
 
-	userID, err := auth.ValidateJWT(token, cfg.jwtSecret)
-	if err != nil {
-		respondWithError(
-			w,
-			http.StatusUnauthorized,
-			"Couldn't validate JWT",
-			err,
-		)
+	params := chirpParams{}
+	var uploads []chirpUpload
+	var err error
+	defer req.Body.Close()
+	if isMultipartRequest(req) {
+		params, uploads, err = decodeChirpMultipart(w, req)
+		if err != nil {
+			respondWithError(w, http.StatusBadRequest, err.Error(), err)
+			return
+		}
+	} else {
+		decoder := json.NewDecoder(req.Body)
+		err = decoder.Decode(&params)
+		if err != nil {
+			respondWithError(w, http.StatusInternalServerError, "Couldn't decode parameters", err)
+			return
+		}
+	}
+
+	createParams, masked, ok := cfg.prepareChirp(w, req, userID, params)
+	if !ok {
 		return
 	}
 
-	decoder := json.NewDecoder(req.Body)
-	params := reqData{}
-	err = decoder.Decode(&params)
-	defer req.Body.Close()
+	var poll *pollParams
+	if params.Poll != nil {
+		validPoll, err := validatePoll(*params.Poll, createParams.PublishAt)
+		if err != nil {
+			respondWithError(w, http.StatusBadRequest, err.Error(), err)
+			return
+		}
+		poll = &validPoll
+	}
 
+	if err := cfg.storeChirpUploads(req.Context(), uploads); err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't store images", err)
+		return
+	}
+	committed := false
+	defer func() {
+		if !committed {
+			cfg.deleteChirpUploads(uploads)
+		}
+	}()
+
+	tx, err := cfg.dbConn.BeginTx(req.Context(), nil)
 	if err != nil {
-		respondWithError(w, http.StatusInternalServerError, "Couldn't decode parameters", err)
+		respondWithError(w, http.StatusInternalServerError, "Couldn't create chirp", err)
 		return
 	}
+	defer tx.Rollback()
+	qtx := cfg.db.WithTx(tx)
 
-	cleaned, err := validateChirp(params.Body)
+	chirp, err := qtx.CreateChirp(req.Context(), createParams)
 	if err != nil {
-		respondWithError(w, http.StatusBadRequest, err.Error(), err)
+		// The insert is skipped when replying to a user who blocked the
+		// author.
+		if errors.Is(err, sql.ErrNoRows) {
+			respondWithError(w, http.StatusForbidden, "Couldn't reply to a user who blocked you", err)
+			return
+		}
+		respondWithError(w, http.StatusInternalServerError, "Couldn't create chirp", err)
 		return
 	}
 
-	chirp, err := cfg.db.CreateChirp(req.Context(), database.CreateChirpParams{
-		Body:   cleaned,
-		UserID: userID,
-	})
+	if err := saveChirpEntities(req.Context(), qtx, chirp); err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't save chirp entities", err)
+		return
+	}
+
+	if err := saveChirpMedia(req.Context(), qtx, chirp.ID, uploads); err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't save chirp media", err)
+		return
+	}
+
+	if poll != nil {
+		if err := savePoll(req.Context(), qtx, chirp.ID, *poll); err != nil {
+			respondWithError(w, http.StatusInternalServerError, "Couldn't save poll", err)
+			return
+		}
+	}
+
+	decision, err := cfg.screenChirp(req.Context(), qtx, chirp)
 	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't screen chirp", err)
+		return
+	}
+
+	if err := tx.Commit(); err != nil {
 		respondWithError(w, http.StatusInternalServerError, "Couldn't create chirp", err)
 		return
 	}
+	committed = true
+	cfg.queueLinkPreviews(entities.URLs(chirp.Body))
+
+	jsonKeysChirp, err := cfg.renderChirp(req.Context(), chirp, userID)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't render chirp", err)
+		return
+	}
 
-	jsonKeysChirp := Chirp{
-		ID:        chirp.ID,
-		CreatedAt: chirp.CreatedAt,
-		UpdatedAt: chirp.UpdatedAt,
-		Body:      chirp.Body,
-		UserId:    chirp.UserID,
+	var moderation *ModerationDecision
+	if decision != nil {
+		res := renderModerationDecision(*decision)
+		moderation = &res
 	}
 
-	respondWithJSON(w, http.StatusCreated, jsonKeysChirp)
+	respondWithJSON(w, http.StatusCreated, response{
+		Chirp:       jsonKeysChirp,
+		MaskedWords: masked,
+		Moderation:  moderation,
+	})
 }
 
-func validateChirp(chirp string) (string, error) {
-	const maxChirpLength = 140
-	if len(chirp) > maxChirpLength {
-		return "", errors.New("Chirp is too long")
+// prepareChirp validates a new chirp and resolves the chirps it refers
+// to. On failure it responds to the request and returns false.
+func (cfg *apiConfig) prepareChirp(w http.ResponseWriter, req *http.Request, userID uuid.UUID, params chirpParams) (database.CreateChirpParams, []string, bool) {
+	ent, err := cfg.entitlements(req.Context(), userID)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't get entitlements", err)
+		return database.CreateChirpParams{}, nil, false
+	}
+
+	cleaned, masked, err := cfg.validateChirp(params.Body, ent)
+	if err != nil {
+		respondWithError(w, limitStatus(err, http.StatusBadRequest), err.Error(), err)
+		return database.CreateChirpParams{}, nil, false
 	}
-	badWords := map[string]struct{}{
-		"kerfuffle": {},
-		"sharbert":  {},
-		"fornax":    {},
+
+	var publishAt sql.NullTime
+	if params.PublishAt != nil {
+		if !params.PublishAt.After(time.Now()) {
+			respondWithError(w, http.StatusBadRequest, "publish_at must be in the future", nil)
+			return database.CreateChirpParams{}, nil, false
+		}
+		publishAt = sql.NullTime{Time: params.PublishAt.UTC(), Valid: true}
+	}
+
+	var replyTo uuid.NullUUID
+	if params.ReplyTo != nil {
+		parent, err := cfg.db.GetChirp(req.Context(), *params.ReplyTo)
+		if err != nil {
+			respondWithError(w, http.StatusNotFound, "Couldn't find chirp to reply to", err)
+			return database.CreateChirpParams{}, nil, false
+		}
+		replyTo = uuid.NullUUID{UUID: parent.ID, Valid: true}
 	}
-	cleaned := getCleanedBody(chirp, badWords)
-	return cleaned, nil
-}
 
-func getCleanedBody(body string, badWords map[string]struct{}) string {
-	words := strings.Split(body, " ")
-	for i, w := range words {
-		if _, ok := badWords[strings.ToLower(w)]; ok {
-			words[i] = "****"
+	var quoteOf uuid.NullUUID
+	if params.QuoteOf != nil {
+		quoted, err := cfg.db.GetChirp(req.Context(), *params.QuoteOf)
+		if err != nil {
+			respondWithError(w, http.StatusNotFound, "Couldn't find chirp to quote", err)
+			return database.CreateChirpParams{}, nil, false
 		}
+		// Quoting a rechirp quotes the chirp that was rechirped.
+		if quoted.RechirpOf.Valid {
+			quoted.ID = quoted.RechirpOf.UUID
+		}
+		quoteOf = uuid.NullUUID{UUID: quoted.ID, Valid: true}
+	}
+
+	return database.CreateChirpParams{
+		Body:      cleaned,
+		UserID:    userID,
+		ReplyTo:   replyTo,
+		QuoteOf:   quoteOf,
+		PublishAt: publishAt,
+	}, masked, true
+}
+
+// validateChirp checks the chirp length against the author's plan and
+// masks banned words. It returns the cleaned body along with the masked
+// words.
+func (cfg *apiConfig) validateChirp(chirp string, ent entitlements.Entitlements) (string, []string, error) {
+	// The plan's limit applies to what the user wrote, not to the
+	// masks, which they can't see coming.
+	if err := ent.CheckChirpLength(chirp); err != nil {
+		return "", nil, err
+	}
+	// Links are left alone, masking would break them.
+	cleaned, masked := cfg.profanity.CleanExcept(chirp, entities.URLIndexes(chirp))
+	// A mask can be longer than the word it replaces, so the stored body
+	// is capped on its own.
+	if len(cleaned) > entitlements.MaxChirpBytes {
+		return "", nil, entitlements.ErrChirpTooLong
+	}
+	if masked == nil {
+		masked = []string{}
 	}
-	return strings.Join(words, " ")
+	return cleaned, masked, nil
 }
@@ -3,7 +3,6 @@ package main
 import (
 	"net/http"
 
-	"github.com/DanilShapilov/chirpy/internal/auth"
 	"github.com/google/uuid"
 )
 
@@ -15,25 +14,8 @@ func (cfg *apiConfig) handlerChirpsDelete(w http.ResponseWriter, req *http.Reque
 		return
 	}
 
-	token, err := auth.GetBearerToken(req.Header)
-	if err != nil {
-		respondWithError(
-			w,
-			http.StatusUnauthorized,
-			"Couldn't find JWT",
-			err,
-		)
-		return
-	}
-
-	userID, err := auth.ValidateJWT(token, cfg.jwtSecret)
-	if err != nil {
-		respondWithError(
-			w,
-			http.StatusUnauthorized,
-			"Couldn't validate JWT",
-			err,
-		)
+	userID, ok := cfg.authenticate(w, req)
+	if !ok {
 		return
 	}
 
@@ -46,7 +28,7 @@ func (cfg *apiConfig) handlerChirpsDelete(w http.ResponseWriter, req *http.Reque
 		respondWithError(w, http.StatusForbidden, "Couldn't delete not own chirp", err)
 		return
 	}
-	err = cfg.db.DeleteChirp(req.Context(), chirp.ID)
+	err = cfg.db.SoftDeleteChirp(req.Context(), chirp.ID)
 	if err != nil {
 		respondWithError(w, http.StatusInternalServerError, "Couldn't delete chirp", err)
 		return
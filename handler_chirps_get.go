@@ -1,8 +1,8 @@
 package main
 
 import (
+	"database/sql"
 	"net/http"
-	"slices"
 
 	"github.com/DanilShapilov/chirpy/internal/database"
 	"github.com/google/uuid"
@@ -11,42 +11,66 @@ import (
 func (cfg *apiConfig) handlerChirpsList(w http.ResponseWriter, req *http.Request) {
 	authorId := req.URL.Query().Get("author_id")
 	sortDir := req.URL.Query().Get("sort")
-	var chirps []database.Chirp
-	var err error
-	var authorUUID uuid.UUID
+	var authorUUID uuid.NullUUID
 	if authorId != "" {
-		authorUUID, err = uuid.Parse(authorId)
+		id, err := uuid.Parse(authorId)
 		if err != nil {
 			respondWithError(w, http.StatusBadRequest, "Incorrect format of author_id", err)
 			return
 		}
-		chirps, err = cfg.db.GetChirpsByAuthor(req.Context(), authorUUID)
+		authorUUID = uuid.NullUUID{UUID: id, Valid: true}
+	}
+
+	page, err := parsePageParams(req.URL.Query())
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, err.Error(), err)
+		return
+	}
+
+	viewerID := cfg.viewerID(req)
+	params := database.ListChirpsParams{
+		AuthorID: authorUUID,
+		ViewerID: nullViewer(viewerID),
+	}
+	if page.Paginated {
+		// Fetch one extra row to find out whether there is a next page.
+		params.Limit = sql.NullInt32{Int32: page.Limit + 1, Valid: true}
+	}
+	if page.Cursor != nil {
+		params.CursorCreatedAt = sql.NullTime{Time: page.Cursor.CreatedAt, Valid: true}
+		params.CursorID = uuid.NullUUID{UUID: page.Cursor.ID, Valid: true}
+	}
+
+	var chirps []database.Chirp
+	if sortDir == "desc" {
+		chirps, err = cfg.db.ListChirpsDesc(req.Context(), database.ListChirpsDescParams(params))
 	} else {
-		chirps, err = cfg.db.GetChirps(req.Context())
+		chirps, err = cfg.db.ListChirps(req.Context(), params)
 	}
 	if err != nil {
 		respondWithError(w, http.StatusInternalServerError, "Couldn't get chirps from db", err)
 		return
 	}
 
-	var res = make([]Chirp, len(chirps))
-	for i, chirp := range chirps {
-		jsonKeysChirp := Chirp{
-			ID:        chirp.ID,
-			CreatedAt: chirp.CreatedAt,
-			UpdatedAt: chirp.UpdatedAt,
-			Body:      chirp.Body,
-			UserId:    chirp.UserID,
-		}
-		res[i] = jsonKeysChirp
+	var nextCursor *string
+	if page.Paginated {
+		chirps, nextCursor = trimChirpsPage(chirps, page.Limit)
 	}
-	if sortDir == "desc" {
-		slices.SortFunc(res, func(a Chirp, b Chirp) int {
-			return b.CreatedAt.Compare(a.CreatedAt)
-		})
+
+	res, err := cfg.renderChirps(req.Context(), chirps, viewerID)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't render chirps", err)
+		return
 	}
 
-	respondWithJSON(w, http.StatusOK, res)
+	if !page.Paginated {
+		respondWithJSON(w, http.StatusOK, res)
+		return
+	}
+	respondWithJSON(w, http.StatusOK, ChirpsPage{
+		Chirps:     res,
+		NextCursor: nextCursor,
+	})
 }
 
 func (cfg *apiConfig) handlerChirpsGet(w http.ResponseWriter, req *http.Request) {
@@ -62,12 +86,10 @@ func (cfg *apiConfig) handlerChirpsGet(w http.ResponseWriter, req *http.Request)
 		return
 	}
 
-	jsonKeysChirp := Chirp{
-		ID:        chirp.ID,
-		CreatedAt: chirp.CreatedAt,
-		UpdatedAt: chirp.UpdatedAt,
-		Body:      chirp.Body,
-		UserId:    chirp.UserID,
+	jsonKeysChirp, err := cfg.renderChirp(req.Context(), chirp, cfg.viewerID(req))
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't render chirp", err)
+		return
 	}
 
 	respondWithJSON(w, http.StatusOK, jsonKeysChirp)
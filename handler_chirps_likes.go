@@ -0,0 +1,120 @@
+package main
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/DanilShapilov/chirpy/internal/database"
+	"github.com/google/uuid"
+)
+
+type ChirpLike struct {
+	UserID    uuid.UUID `json:"user_id"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func (cfg *apiConfig) handlerChirpLikesCreate(w http.ResponseWriter, req *http.Request) {
+	chirpIDString := req.PathValue("chirpID")
+	chirpID, err := uuid.Parse(chirpIDString)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid chirp ID", err)
+		return
+	}
+
+	userID, ok := cfg.authenticate(w, req)
+	if !ok {
+		return
+	}
+
+	chirp, err := cfg.db.GetChirp(req.Context(), chirpID)
+	if err != nil {
+		respondWithError(w, http.StatusNotFound, "Couldn't find chirp", err)
+		return
+	}
+
+	liked, err := cfg.db.LikeChirp(req.Context(), database.LikeChirpParams{
+		UserID:  userID,
+		ChirpID: chirp.ID,
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't like chirp", err)
+		return
+	}
+	// Nothing is inserted for a repeated like or when the author blocked
+	// the user, only the latter is an error.
+	if liked == 0 {
+		blocked, err := cfg.db.IsBlockedBy(req.Context(), database.IsBlockedByParams{
+			BlockerID: chirp.UserID,
+			BlockedID: userID,
+		})
+		if err != nil {
+			respondWithError(w, http.StatusInternalServerError, "Couldn't like chirp", err)
+			return
+		}
+		if blocked {
+			respondWithError(w, http.StatusForbidden, "Couldn't like chirp of a user who blocked you", nil)
+			return
+		}
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (cfg *apiConfig) handlerChirpLikesDelete(w http.ResponseWriter, req *http.Request) {
+	chirpIDString := req.PathValue("chirpID")
+	chirpID, err := uuid.Parse(chirpIDString)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid chirp ID", err)
+		return
+	}
+
+	userID, ok := cfg.authenticate(w, req)
+	if !ok {
+		return
+	}
+
+	_, err = cfg.db.UnlikeChirp(req.Context(), database.UnlikeChirpParams{
+		UserID:  userID,
+		ChirpID: chirpID,
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't unlike chirp", err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (cfg *apiConfig) handlerChirpLikesList(w http.ResponseWriter, req *http.Request) {
+	chirpIDString := req.PathValue("chirpID")
+	chirpID, err := uuid.Parse(chirpIDString)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid chirp ID", err)
+		return
+	}
+
+	chirp, err := cfg.db.GetChirp(req.Context(), chirpID)
+	if err != nil {
+		respondWithError(w, http.StatusNotFound, "Couldn't find chirp", err)
+		return
+	}
+
+	likes, err := cfg.db.GetChirpLikes(req.Context(), database.GetChirpLikesParams{
+		ChirpID:  chirp.ID,
+		ViewerID: nullViewer(cfg.viewerID(req)),
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't get chirp likes", err)
+		return
+	}
+
+	res := make([]ChirpLike, len(likes))
+	for i, like := range likes {
+		res[i] = ChirpLike{
+			UserID:    like.UserID,
+			CreatedAt: like.CreatedAt,
+		}
+	}
+
+	respondWithJSON(w, http.StatusOK, res)
+}
@@ -0,0 +1,236 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"mime"
+	"net/http"
+	"os"
+	"path"
+	"time"
+	"unicode/utf8"
+
+	"github.com/DanilShapilov/chirpy/internal/database"
+	"github.com/DanilShapilov/chirpy/internal/media"
+	"github.com/google/uuid"
+)
+
+const (
+	maxChirpImages    = 4
+	maxChirpImageSize = 5 << 20
+	// maxChirpFormSize leaves some room for the text fields.
+	maxChirpFormSize = maxChirpImages*maxChirpImageSize + 1<<20
+	// maxAltTextLength is counted in characters.
+	maxAltTextLength = 1000
+)
+
+type ChirpMedia struct {
+	URL             string `json:"url"`
+	ThumbnailURL    string `json:"thumbnail_url"`
+	ContentType     string `json:"content_type"`
+	Width           int32  `json:"width"`
+	Height          int32  `json:"height"`
+	ThumbnailWidth  int32  `json:"thumbnail_width"`
+	ThumbnailHeight int32  `json:"thumbnail_height"`
+	AltText         string `json:"alt_text"`
+}
+
+type chirpUpload struct {
+	image   media.Image
+	altText string
+
+	key          string
+	thumbnailKey string
+}
+
+func isMultipartRequest(req *http.Request) bool {
+	mediaType, _, err := mime.ParseMediaType(req.Header.Get("Content-Type"))
+	return err == nil && mediaType == "multipart/form-data"
+}
+
+// decodeChirpMultipart reads a multipart/form-data chirp: the body,
+// reply_to, quote_of and publish_at text fields, an optional poll given
+// as repeated "poll_options" and "poll_expires_at", up to maxChirpImages
+// files named "images" and an optional "alt_text" field per image, in the
+// same order as the files.
+func decodeChirpMultipart(w http.ResponseWriter, req *http.Request) (chirpParams, []chirpUpload, error) {
+	req.Body = http.MaxBytesReader(w, req.Body, maxChirpFormSize)
+	if err := req.ParseMultipartForm(maxChirpFormSize); err != nil {
+		return chirpParams{}, nil, fmt.Errorf("Couldn't parse multipart form: %w", err)
+	}
+	defer req.MultipartForm.RemoveAll()
+
+	params := chirpParams{Body: req.FormValue("body")}
+	var err error
+	params.ReplyTo, err = formUUID(req, "reply_to")
+	if err != nil {
+		return chirpParams{}, nil, err
+	}
+	params.QuoteOf, err = formUUID(req, "quote_of")
+	if err != nil {
+		return chirpParams{}, nil, err
+	}
+	if publishAt := req.FormValue("publish_at"); publishAt != "" {
+		t, err := time.Parse(time.RFC3339, publishAt)
+		if err != nil {
+			return chirpParams{}, nil, errors.New("Incorrect format of publish_at")
+		}
+		params.PublishAt = &t
+	}
+	if options := req.MultipartForm.Value["poll_options"]; len(options) > 0 {
+		expiresAt, err := time.Parse(time.RFC3339, req.FormValue("poll_expires_at"))
+		if err != nil {
+			return chirpParams{}, nil, errors.New("Incorrect format of poll_expires_at")
+		}
+		params.Poll = &pollParams{
+			Options:   options,
+			ExpiresAt: expiresAt,
+		}
+	}
+
+	files := req.MultipartForm.File["images"]
+	if len(files) > maxChirpImages {
+		return chirpParams{}, nil, fmt.Errorf("Chirp can have at most %d images", maxChirpImages)
+	}
+	altTexts := req.MultipartForm.Value["alt_text"]
+
+	uploads := make([]chirpUpload, len(files))
+	for i, header := range files {
+		if header.Size > maxChirpImageSize {
+			return chirpParams{}, nil, fmt.Errorf("Image %d is larger than %d bytes", i+1, maxChirpImageSize)
+		}
+		file, err := header.Open()
+		if err != nil {
+			return chirpParams{}, nil, fmt.Errorf("Couldn't read image %d: %w", i+1, err)
+		}
+		data, err := io.ReadAll(io.LimitReader(file, maxChirpImageSize))
+		file.Close()
+		if err != nil {
+			return chirpParams{}, nil, fmt.Errorf("Couldn't read image %d: %w", i+1, err)
+		}
+
+		img, err := media.Process(data)
+		if err != nil {
+			return chirpParams{}, nil, fmt.Errorf("Invalid image %d: %w", i+1, err)
+		}
+		uploads[i] = chirpUpload{image: img}
+		if i < len(altTexts) {
+			if utf8.RuneCountInString(altTexts[i]) > maxAltTextLength {
+				return chirpParams{}, nil, fmt.Errorf("Alt text of image %d is longer than %d characters", i+1, maxAltTextLength)
+			}
+			uploads[i].altText = altTexts[i]
+		}
+	}
+
+	return params, uploads, nil
+}
+
+// handlerMediaGet serves a chirp image or thumbnail. Only media of
+// chirps that are visible is served, so deleting or hiding a chirp takes
+// its images down too.
+func (cfg *apiConfig) handlerMediaGet(w http.ResponseWriter, req *http.Request) {
+	key := req.PathValue("key")
+
+	visible, err := cfg.db.IsChirpMediaVisible(req.Context(), key)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't get media", err)
+		return
+	}
+	if !visible {
+		respondWithError(w, http.StatusNotFound, "Couldn't find media", nil)
+		return
+	}
+
+	f, err := cfg.storage.Open(req.Context(), key)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			respondWithError(w, http.StatusNotFound, "Couldn't find media", err)
+			return
+		}
+		respondWithError(w, http.StatusInternalServerError, "Couldn't get media", err)
+		return
+	}
+	defer f.Close()
+
+	// Keys are never reused, but a chirp can be hidden at any time.
+	w.Header().Set("Cache-Control", "public, max-age=300")
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	http.ServeContent(w, req, path.Base(key), time.Time{}, f)
+}
+
+// formUUID parses an optional UUID form field.
+func formUUID(req *http.Request, key string) (*uuid.UUID, error) {
+	value := req.FormValue(key)
+	if value == "" {
+		return nil, nil
+	}
+	id, err := uuid.Parse(value)
+	if err != nil {
+		return nil, fmt.Errorf("Incorrect format of %s", key)
+	}
+	return &id, nil
+}
+
+// storeChirpUploads writes the images and thumbnails to storage and sets
+// their keys. Nothing is left behind in storage when it fails.
+func (cfg *apiConfig) storeChirpUploads(ctx context.Context, uploads []chirpUpload) error {
+	for i := range uploads {
+		upload := &uploads[i]
+		name := "chirps/" + uuid.NewString()
+		upload.key = name + media.Extension(upload.image.ContentType)
+		upload.thumbnailKey = name + "_thumb" + media.Extension(upload.image.ThumbnailContentType)
+
+		if err := cfg.storage.Put(ctx, upload.key, upload.image.Data); err != nil {
+			cfg.deleteChirpUploads(uploads[:i+1])
+			return err
+		}
+		if err := cfg.storage.Put(ctx, upload.thumbnailKey, upload.image.Thumbnail); err != nil {
+			cfg.deleteChirpUploads(uploads[:i+1])
+			return err
+		}
+	}
+	return nil
+}
+
+func (cfg *apiConfig) deleteChirpUploads(uploads []chirpUpload) {
+	for _, upload := range uploads {
+		cfg.deleteMediaFiles(upload.key, upload.thumbnailKey)
+	}
+}
+
+// deleteMediaFiles removes blobs from storage on a best effort basis.
+// It runs during cleanup, so failures are only logged.
+func (cfg *apiConfig) deleteMediaFiles(keys ...string) {
+	for _, key := range keys {
+		if key == "" {
+			continue
+		}
+		if err := cfg.storage.Delete(context.Background(), key); err != nil {
+			log.Printf("Couldn't delete media %s: %s", key, err)
+		}
+	}
+}
+
+func saveChirpMedia(ctx context.Context, q *database.Queries, chirpID uuid.UUID, uploads []chirpUpload) error {
+	for i, upload := range uploads {
+		_, err := q.CreateChirpMedia(ctx, database.CreateChirpMediaParams{
+			ChirpID:         chirpID,
+			Position:        int32(i),
+			StorageKey:      upload.key,
+			ThumbnailKey:    upload.thumbnailKey,
+			ContentType:     upload.image.ContentType,
+			Width:           int32(upload.image.Width),
+			Height:          int32(upload.image.Height),
+			ThumbnailWidth:  int32(upload.image.ThumbnailWidth),
+			ThumbnailHeight: int32(upload.image.ThumbnailHeight),
+			AltText:         upload.altText,
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/DanilShapilov/chirpy/internal/database"
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+// handlerPollVotesCreate records the caller's vote and responds with the
+// chirp and its updated tallies. Votes are final and only accepted until
+// the poll expires, which freezes the results.
+func (cfg *apiConfig) handlerPollVotesCreate(w http.ResponseWriter, req *http.Request) {
+	type reqData struct {
+		Option *int32 `json:"option"`
+	}
+
+	chirpIDString := req.PathValue("chirpID")
+	chirpID, err := uuid.Parse(chirpIDString)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid chirp ID", err)
+		return
+	}
+
+	userID, ok := cfg.authenticate(w, req)
+	if !ok {
+		return
+	}
+
+	defer req.Body.Close()
+	decoder := json.NewDecoder(req.Body)
+	params := reqData{}
+	err = decoder.Decode(&params)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't decode parameters", err)
+		return
+	}
+	if params.Option == nil {
+		respondWithError(w, http.StatusBadRequest, "Poll option is required", nil)
+		return
+	}
+
+	chirp, err := cfg.db.GetChirp(req.Context(), chirpID)
+	if err != nil {
+		respondWithError(w, http.StatusNotFound, "Couldn't find chirp", err)
+		return
+	}
+	if _, err := cfg.db.GetPoll(req.Context(), chirp.ID); err != nil {
+		respondWithError(w, http.StatusNotFound, "Chirp has no poll", err)
+		return
+	}
+
+	_, err = cfg.db.CreatePollVote(req.Context(), database.CreatePollVoteParams{
+		UserID:   userID,
+		Position: *params.Option,
+		ChirpID:  chirp.ID,
+	})
+	if err != nil {
+		var pqErr *pq.Error
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			respondWithError(w, http.StatusForbidden, "Poll is closed", err)
+		case isUniqueViolation(err):
+			respondWithError(w, http.StatusConflict, "Already voted in this poll", err)
+		case errors.As(err, &pqErr) && pqErr.Code == "23503":
+			respondWithError(w, http.StatusBadRequest, "Invalid poll option", err)
+		default:
+			respondWithError(w, http.StatusInternalServerError, "Couldn't vote in poll", err)
+		}
+		return
+	}
+
+	jsonKeysChirp, err := cfg.renderChirp(req.Context(), chirp, userID)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't render chirp", err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusCreated, jsonKeysChirp)
+}
@@ -0,0 +1,59 @@
+package main
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+
+	"github.com/DanilShapilov/chirpy/internal/database"
+	"github.com/google/uuid"
+)
+
+func (cfg *apiConfig) handlerChirpsRechirp(w http.ResponseWriter, req *http.Request) {
+	chirpIDString := req.PathValue("chirpID")
+	chirpID, err := uuid.Parse(chirpIDString)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid chirp ID", err)
+		return
+	}
+
+	userID, ok := cfg.authenticate(w, req)
+	if !ok {
+		return
+	}
+
+	original, err := cfg.db.GetChirp(req.Context(), chirpID)
+	if err != nil {
+		respondWithError(w, http.StatusNotFound, "Couldn't find chirp", err)
+		return
+	}
+	// Rechirping a rechirp rechirps the chirp it points to.
+	if original.RechirpOf.Valid {
+		original, err = cfg.db.GetChirp(req.Context(), original.RechirpOf.UUID)
+		if err != nil {
+			respondWithError(w, http.StatusNotFound, "Couldn't find chirp", err)
+			return
+		}
+	}
+
+	rechirp, err := cfg.db.CreateRechirp(req.Context(), database.CreateRechirpParams{
+		UserID:    userID,
+		RechirpOf: uuid.NullUUID{UUID: original.ID, Valid: true},
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			respondWithError(w, http.StatusConflict, "Chirp is already rechirped", err)
+			return
+		}
+		respondWithError(w, http.StatusInternalServerError, "Couldn't rechirp chirp", err)
+		return
+	}
+
+	jsonKeysChirp, err := cfg.renderChirp(req.Context(), rechirp, userID)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't render chirp", err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusCreated, jsonKeysChirp)
+}
@@ -0,0 +1,65 @@
+package main
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/DanilShapilov/chirpy/internal/database"
+	"github.com/google/uuid"
+)
+
+func (cfg *apiConfig) handlerChirpsRestore(w http.ResponseWriter, req *http.Request) {
+	chirpIDString := req.PathValue("chirpID")
+	chirpID, err := uuid.Parse(chirpIDString)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid chirp ID", err)
+		return
+	}
+
+	userID, ok := cfg.authenticate(w, req)
+	if !ok {
+		return
+	}
+
+	chirp, err := cfg.db.GetDeletedChirp(req.Context(), chirpID)
+	if err != nil {
+		respondWithError(w, http.StatusNotFound, "Couldn't find deleted chirp", err)
+		return
+	}
+	if chirp.UserID != userID {
+		respondWithError(w, http.StatusForbidden, "Couldn't restore not own chirp", nil)
+		return
+	}
+
+	chirp, err = cfg.db.RestoreChirp(req.Context(), database.RestoreChirpParams{
+		ID: chirp.ID,
+		DeletedAfter: sql.NullTime{
+			Time:  time.Now().Add(-cfg.chirpRestoreWindow).UTC(),
+			Valid: true,
+		},
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			respondWithError(w, http.StatusGone, "Restore window has expired", err)
+			return
+		}
+		// A rechirp can't come back while the user has rechirped the same
+		// chirp again.
+		if isUniqueViolation(err) {
+			respondWithError(w, http.StatusConflict, "Chirp is already rechirped", err)
+			return
+		}
+		respondWithError(w, http.StatusInternalServerError, "Couldn't restore chirp", err)
+		return
+	}
+
+	jsonKeysChirp, err := cfg.renderChirp(req.Context(), chirp, userID)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't render chirp", err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, jsonKeysChirp)
+}
@@ -0,0 +1,50 @@
+package main
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type ChirpRevision struct {
+	ID         uuid.UUID `json:"id"`
+	ChirpID    uuid.UUID `json:"chirp_id"`
+	CreatedAt  time.Time `json:"created_at"`
+	ReplacedAt time.Time `json:"replaced_at"`
+	Body       string    `json:"body"`
+}
+
+func (cfg *apiConfig) handlerChirpRevisionsList(w http.ResponseWriter, req *http.Request) {
+	chirpIDString := req.PathValue("chirpID")
+	chirpID, err := uuid.Parse(chirpIDString)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid chirp ID", err)
+		return
+	}
+
+	chirp, err := cfg.db.GetChirp(req.Context(), chirpID)
+	if err != nil {
+		respondWithError(w, http.StatusNotFound, "Couldn't find chirp", err)
+		return
+	}
+
+	revisions, err := cfg.db.GetChirpRevisions(req.Context(), chirp.ID)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't get chirp revisions", err)
+		return
+	}
+
+	res := make([]ChirpRevision, len(revisions))
+	for i, revision := range revisions {
+		res[i] = ChirpRevision{
+			ID:         revision.ID,
+			ChirpID:    revision.ChirpID,
+			CreatedAt:  revision.CreatedAt,
+			ReplacedAt: revision.ReplacedAt,
+			Body:       revision.Body,
+		}
+	}
+
+	respondWithJSON(w, http.StatusOK, res)
+}
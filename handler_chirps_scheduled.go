@@ -0,0 +1,73 @@
+package main
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+
+	"github.com/DanilShapilov/chirpy/internal/database"
+	"github.com/google/uuid"
+)
+
+func (cfg *apiConfig) handlerScheduledChirpsList(w http.ResponseWriter, req *http.Request) {
+	userID, ok := cfg.authenticate(w, req)
+	if !ok {
+		return
+	}
+
+	dbChirps, err := cfg.db.ListScheduledChirps(req.Context(), userID)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't get scheduled chirps", err)
+		return
+	}
+
+	chirps, err := cfg.renderChirps(req.Context(), dbChirps, userID)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't render chirps", err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, chirps)
+}
+
+// handlerScheduledChirpsDelete cancels a scheduled chirp. The chirp is
+// taken from the chirp_id query parameter: a /scheduled/{chirpID} route
+// would clash with DELETE /api/chirps/{chirpID}/likes.
+func (cfg *apiConfig) handlerScheduledChirpsDelete(w http.ResponseWriter, req *http.Request) {
+	chirpID, err := uuid.Parse(req.URL.Query().Get("chirp_id"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid chirp ID", err)
+		return
+	}
+
+	userID, ok := cfg.authenticate(w, req)
+	if !ok {
+		return
+	}
+
+	// Collect the files first, the rows are gone after the delete.
+	chirpMedia, err := cfg.db.GetChirpMedia(req.Context(), []uuid.UUID{chirpID})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't get chirp media", err)
+		return
+	}
+
+	_, err = cfg.db.DeleteScheduledChirp(req.Context(), database.DeleteScheduledChirpParams{
+		ID:     chirpID,
+		UserID: userID,
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			respondWithError(w, http.StatusNotFound, "Couldn't find scheduled chirp", err)
+			return
+		}
+		respondWithError(w, http.StatusInternalServerError, "Couldn't delete scheduled chirp", err)
+		return
+	}
+
+	for _, m := range chirpMedia {
+		cfg.deleteMediaFiles(m.StorageKey, m.ThumbnailKey)
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,90 @@
+package main
+
+import (
+	"html"
+	"net/http"
+	"strings"
+
+	"github.com/DanilShapilov/chirpy/internal/database"
+	"github.com/DanilShapilov/chirpy/internal/entitlements"
+	"github.com/google/uuid"
+)
+
+type ChirpSearchResult struct {
+	Chirp
+	Rank float32 `json:"rank"`
+	// Highlight is the body as HTML, escaped, with the matched words
+	// wrapped in <mark> tags.
+	Highlight string `json:"highlight"`
+}
+
+// SearchChirps has ts_headline mark matches with these control
+// characters, which it strips from bodies beforehand. Chirp text can
+// contain markup, so the markers only become tags after escaping.
+var highlightReplacer = strings.NewReplacer(
+	"\x02", "<mark>",
+	"\x03", "</mark>",
+)
+
+func renderHighlight(headline string) string {
+	return highlightReplacer.Replace(html.EscapeString(headline))
+}
+
+func (cfg *apiConfig) handlerChirpsSearch(w http.ResponseWriter, req *http.Request) {
+	query := strings.TrimSpace(req.URL.Query().Get("q"))
+	if query == "" {
+		respondWithError(w, http.StatusBadRequest, "Search query couldn't be empty", nil)
+		return
+	}
+
+	authorId := req.URL.Query().Get("author_id")
+	var authorUUID uuid.NullUUID
+	if authorId != "" {
+		id, err := uuid.Parse(authorId)
+		if err != nil {
+			respondWithError(w, http.StatusBadRequest, "Incorrect format of author_id", err)
+			return
+		}
+		authorUUID = uuid.NullUUID{UUID: id, Valid: true}
+	}
+
+	limit, err := parseLimit(req.URL.Query())
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, err.Error(), err)
+		return
+	}
+
+	viewerID := cfg.viewerID(req)
+	rows, err := cfg.db.SearchChirps(req.Context(), database.SearchChirpsParams{
+		RedBoost: entitlements.Red.SearchBoost,
+		Query:    query,
+		AuthorID: authorUUID,
+		ViewerID: nullViewer(viewerID),
+		Limit:    limit,
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't search chirps", err)
+		return
+	}
+
+	chirps := make([]database.Chirp, len(rows))
+	for i, row := range rows {
+		chirps[i] = row.Chirp
+	}
+	rendered, err := cfg.renderChirps(req.Context(), chirps, viewerID)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't render chirps", err)
+		return
+	}
+
+	res := make([]ChirpSearchResult, len(rows))
+	for i, row := range rows {
+		res[i] = ChirpSearchResult{
+			Chirp:     rendered[i],
+			Rank:      row.Rank,
+			Highlight: renderHighlight(row.Highlight),
+		}
+	}
+
+	respondWithJSON(w, http.StatusOK, res)
+}
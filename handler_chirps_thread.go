@@ -0,0 +1,107 @@
+package main
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/DanilShapilov/chirpy/internal/database"
+	"github.com/google/uuid"
+)
+
+const (
+	defaultThreadDepth = 5
+	maxThreadDepth     = 20
+)
+
+type ChirpThread struct {
+	Chirp
+	Replies []ChirpThread `json:"replies"`
+}
+
+func (cfg *apiConfig) handlerChirpsThread(w http.ResponseWriter, req *http.Request) {
+	chirpIDString := req.PathValue("chirpID")
+	chirpID, err := uuid.Parse(chirpIDString)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid chirp ID", err)
+		return
+	}
+
+	depth := defaultThreadDepth
+	if depthStr := req.URL.Query().Get("depth"); depthStr != "" {
+		depth, err = strconv.Atoi(depthStr)
+		if err != nil || depth < 0 || depth > maxThreadDepth {
+			respondWithError(w, http.StatusBadRequest, "depth must be between 0 and "+strconv.Itoa(maxThreadDepth), err)
+			return
+		}
+	}
+
+	rootID, err := cfg.db.GetChirpThreadRoot(req.Context(), chirpID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			respondWithError(w, http.StatusNotFound, "Couldn't find chirp", err)
+			return
+		}
+		respondWithError(w, http.StatusInternalServerError, "Couldn't get thread", err)
+		return
+	}
+
+	viewerID := cfg.viewerID(req)
+	rows, err := cfg.db.GetChirpReplyTree(req.Context(), database.GetChirpReplyTreeParams{
+		RootID:   rootID,
+		MaxDepth: int32(depth),
+		ViewerID: nullViewer(viewerID),
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't get thread", err)
+		return
+	}
+	if len(rows) == 0 {
+		respondWithError(w, http.StatusNotFound, "Couldn't find chirp", nil)
+		return
+	}
+
+	chirps := make([]database.Chirp, len(rows))
+	for i, row := range rows {
+		chirps[i] = database.Chirp{
+			ID:        row.ID,
+			CreatedAt: row.CreatedAt,
+			UpdatedAt: row.UpdatedAt,
+			Body:      row.Body,
+			UserID:    row.UserID,
+			ReplyTo:   row.ReplyTo,
+			RechirpOf: row.RechirpOf,
+			QuoteOf:   row.QuoteOf,
+		}
+	}
+	rendered, err := cfg.renderChirps(req.Context(), chirps, viewerID)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't render chirps", err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, buildChirpThread(rendered))
+}
+
+// buildChirpThread assembles a reply tree from chirps ordered by depth,
+// so every parent comes before its replies. The first chirp is the root.
+func buildChirpThread(chirps []Chirp) ChirpThread {
+	replies := make(map[uuid.UUID][]Chirp, len(chirps))
+	for _, chirp := range chirps[1:] {
+		replies[*chirp.ReplyTo] = append(replies[*chirp.ReplyTo], chirp)
+	}
+
+	var build func(chirp Chirp) ChirpThread
+	build = func(chirp Chirp) ChirpThread {
+		node := ChirpThread{
+			Chirp:   chirp,
+			Replies: make([]ChirpThread, 0, len(replies[chirp.ID])),
+		}
+		for _, reply := range replies[chirp.ID] {
+			node.Replies = append(node.Replies, build(reply))
+		}
+		return node
+	}
+	return build(chirps[0])
+}
@@ -0,0 +1,166 @@
+package main
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/DanilShapilov/chirpy/internal/database"
+	"github.com/DanilShapilov/chirpy/internal/entities"
+	"github.com/google/uuid"
+)
+
+func (cfg *apiConfig) handlerChirpsUpdate(w http.ResponseWriter, req *http.Request) {
+	type reqData struct {
+		Body string      `json:"body"`
+		Poll *pollParams `json:"poll"`
+	}
+	type response struct {
+		Chirp
+		MaskedWords []string            `json:"masked_words"`
+		Moderation  *ModerationDecision `json:"moderation,omitempty"`
+	}
+
+	chirpIDString := req.PathValue("chirpID")
+	chirpID, err := uuid.Parse(chirpIDString)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid chirp ID", err)
+		return
+	}
+
+	userID, ok := cfg.authenticate(w, req)
+	if !ok {
+		return
+	}
+
+	decoder := json.NewDecoder(req.Body)
+	params := reqData{}
+	err = decoder.Decode(&params)
+	defer req.Body.Close()
+
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't decode parameters", err)
+		return
+	}
+
+	ent, err := cfg.entitlements(req.Context(), userID)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't get entitlements", err)
+		return
+	}
+
+	cleaned, masked, err := cfg.validateChirp(params.Body, ent)
+	if err != nil {
+		respondWithError(w, limitStatus(err, http.StatusBadRequest), err.Error(), err)
+		return
+	}
+
+	var poll *pollParams
+	if params.Poll != nil {
+		validPoll, err := validatePoll(*params.Poll, sql.NullTime{})
+		if err != nil {
+			respondWithError(w, http.StatusBadRequest, err.Error(), err)
+			return
+		}
+		poll = &validPoll
+	}
+
+	tx, err := cfg.dbConn.BeginTx(req.Context(), nil)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't update chirp", err)
+		return
+	}
+	defer tx.Rollback()
+	qtx := cfg.db.WithTx(tx)
+
+	chirp, err := qtx.GetChirpForUpdate(req.Context(), chirpID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			respondWithError(w, http.StatusNotFound, "Couldn't find chirp", err)
+			return
+		}
+		respondWithError(w, http.StatusInternalServerError, "Couldn't get chirp", err)
+		return
+	}
+	if chirp.UserID != userID {
+		respondWithError(w, http.StatusForbidden, "Couldn't edit not own chirp", nil)
+		return
+	}
+	if chirp.RechirpOf.Valid {
+		respondWithError(w, http.StatusBadRequest, "Couldn't edit a rechirp", nil)
+		return
+	}
+	if err := ent.CheckEdit(chirp.CreatedAt, time.Now()); err != nil {
+		respondWithError(w, limitStatus(err, http.StatusForbidden), err.Error(), err)
+		return
+	}
+
+	_, err = qtx.CreateChirpRevision(req.Context(), database.CreateChirpRevisionParams{
+		ChirpID:   chirp.ID,
+		CreatedAt: chirp.UpdatedAt,
+		Body:      chirp.Body,
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't save chirp revision", err)
+		return
+	}
+
+	chirp, err = qtx.UpdateChirpBody(req.Context(), database.UpdateChirpBodyParams{
+		Body: cleaned,
+		ID:   chirp.ID,
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't update chirp", err)
+		return
+	}
+
+	if err := saveChirpEntities(req.Context(), qtx, chirp); err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't save chirp entities", err)
+		return
+	}
+
+	if poll != nil {
+		if err := replacePoll(req.Context(), qtx, chirp.ID, *poll); err != nil {
+			if errors.Is(err, errPollHasVotes) {
+				respondWithError(w, http.StatusConflict, err.Error(), err)
+				return
+			}
+			respondWithError(w, http.StatusInternalServerError, "Couldn't save poll", err)
+			return
+		}
+	}
+
+	// Edits go through the same checkers as new chirps, or links and
+	// copies could be added to a chirp after it passed them.
+	decision, err := cfg.screenChirp(req.Context(), qtx, chirp)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't screen chirp", err)
+		return
+	}
+
+	if err := tx.Commit(); err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't update chirp", err)
+		return
+	}
+	cfg.queueLinkPreviews(entities.URLs(chirp.Body))
+
+	jsonKeysChirp, err := cfg.renderChirp(req.Context(), chirp, userID)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't render chirp", err)
+		return
+	}
+
+	var moderation *ModerationDecision
+	if decision != nil {
+		res := renderModerationDecision(*decision)
+		moderation = &res
+	}
+
+	respondWithJSON(w, http.StatusOK, response{
+		Chirp:       jsonKeysChirp,
+		MaskedWords: masked,
+		Moderation:  moderation,
+	})
+}
@@ -0,0 +1,294 @@
+package main
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/DanilShapilov/chirpy/internal/database"
+	"github.com/DanilShapilov/chirpy/internal/entities"
+	"github.com/DanilShapilov/chirpy/internal/entitlements"
+	"github.com/google/uuid"
+)
+
+type Draft struct {
+	ID        uuid.UUID  `json:"id"`
+	CreatedAt time.Time  `json:"created_at"`
+	UpdatedAt time.Time  `json:"updated_at"`
+	UserID    uuid.UUID  `json:"user_id"`
+	Body      string     `json:"body"`
+	ReplyTo   *uuid.UUID `json:"reply_to"`
+	QuoteOf   *uuid.UUID `json:"quote_of"`
+}
+
+// draftParams is the body of POST and PUT /api/drafts. Only the size of
+// the body is checked, everything else is validated when the draft is
+// published.
+type draftParams struct {
+	Body    string     `json:"body"`
+	ReplyTo *uuid.UUID `json:"reply_to"`
+	QuoteOf *uuid.UUID `json:"quote_of"`
+}
+
+func (cfg *apiConfig) handlerDraftsCreate(w http.ResponseWriter, req *http.Request) {
+	userID, ok := cfg.authenticate(w, req)
+	if !ok {
+		return
+	}
+
+	defer req.Body.Close()
+	decoder := json.NewDecoder(req.Body)
+	params := draftParams{}
+	err := decoder.Decode(&params)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't decode parameters", err)
+		return
+	}
+	if len(params.Body) > entitlements.MaxChirpBytes {
+		respondWithError(w, http.StatusBadRequest, entitlements.ErrChirpTooLong.Error(), nil)
+		return
+	}
+
+	draft, err := cfg.db.CreateDraft(req.Context(), database.CreateDraftParams{
+		UserID:  userID,
+		Body:    params.Body,
+		ReplyTo: toNullUUID(params.ReplyTo),
+		QuoteOf: toNullUUID(params.QuoteOf),
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't create draft", err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusCreated, renderDraft(draft))
+}
+
+func (cfg *apiConfig) handlerDraftsUpdate(w http.ResponseWriter, req *http.Request) {
+	draftIDString := req.PathValue("draftID")
+	draftID, err := uuid.Parse(draftIDString)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid draft ID", err)
+		return
+	}
+
+	userID, ok := cfg.authenticate(w, req)
+	if !ok {
+		return
+	}
+
+	defer req.Body.Close()
+	decoder := json.NewDecoder(req.Body)
+	params := draftParams{}
+	err = decoder.Decode(&params)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't decode parameters", err)
+		return
+	}
+	if len(params.Body) > entitlements.MaxChirpBytes {
+		respondWithError(w, http.StatusBadRequest, entitlements.ErrChirpTooLong.Error(), nil)
+		return
+	}
+
+	draft, err := cfg.db.UpdateDraft(req.Context(), database.UpdateDraftParams{
+		Body:    params.Body,
+		ReplyTo: toNullUUID(params.ReplyTo),
+		QuoteOf: toNullUUID(params.QuoteOf),
+		ID:      draftID,
+		UserID:  userID,
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			respondWithError(w, http.StatusNotFound, "Couldn't find draft", err)
+			return
+		}
+		respondWithError(w, http.StatusInternalServerError, "Couldn't update draft", err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, renderDraft(draft))
+}
+
+func (cfg *apiConfig) handlerDraftsList(w http.ResponseWriter, req *http.Request) {
+	userID, ok := cfg.authenticate(w, req)
+	if !ok {
+		return
+	}
+
+	dbDrafts, err := cfg.db.ListDrafts(req.Context(), userID)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't get drafts", err)
+		return
+	}
+
+	drafts := make([]Draft, len(dbDrafts))
+	for i, draft := range dbDrafts {
+		drafts[i] = renderDraft(draft)
+	}
+
+	respondWithJSON(w, http.StatusOK, drafts)
+}
+
+func (cfg *apiConfig) handlerDraftsDelete(w http.ResponseWriter, req *http.Request) {
+	draftIDString := req.PathValue("draftID")
+	draftID, err := uuid.Parse(draftIDString)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid draft ID", err)
+		return
+	}
+
+	userID, ok := cfg.authenticate(w, req)
+	if !ok {
+		return
+	}
+
+	deleted, err := cfg.db.DeleteDraft(req.Context(), database.DeleteDraftParams{
+		ID:     draftID,
+		UserID: userID,
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't delete draft", err)
+		return
+	}
+	if deleted == 0 {
+		respondWithError(w, http.StatusNotFound, "Couldn't find draft", nil)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// handlerDraftsPublish turns a draft into a chirp. The draft goes through
+// the same checks as POST /api/chirps and is removed in the same
+// transaction that creates the chirp.
+func (cfg *apiConfig) handlerDraftsPublish(w http.ResponseWriter, req *http.Request) {
+	type response struct {
+		Chirp
+		MaskedWords []string            `json:"masked_words"`
+		Moderation  *ModerationDecision `json:"moderation,omitempty"`
+	}
+
+	draftIDString := req.PathValue("draftID")
+	draftID, err := uuid.Parse(draftIDString)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid draft ID", err)
+		return
+	}
+
+	userID, ok := cfg.authenticate(w, req)
+	if !ok {
+		return
+	}
+
+	tx, err := cfg.dbConn.BeginTx(req.Context(), nil)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't publish draft", err)
+		return
+	}
+	defer tx.Rollback()
+	qtx := cfg.db.WithTx(tx)
+
+	draft, err := qtx.GetDraftForUpdate(req.Context(), database.GetDraftForUpdateParams{
+		ID:     draftID,
+		UserID: userID,
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			respondWithError(w, http.StatusNotFound, "Couldn't find draft", err)
+			return
+		}
+		respondWithError(w, http.StatusInternalServerError, "Couldn't get draft", err)
+		return
+	}
+
+	draftJSON := renderDraft(draft)
+	createParams, masked, ok := cfg.prepareChirp(w, req, userID, chirpParams{
+		Body:    draftJSON.Body,
+		ReplyTo: draftJSON.ReplyTo,
+		QuoteOf: draftJSON.QuoteOf,
+	})
+	if !ok {
+		return
+	}
+
+	chirp, err := qtx.CreateChirp(req.Context(), createParams)
+	if err != nil {
+		// The insert is skipped when replying to a user who blocked the
+		// author.
+		if errors.Is(err, sql.ErrNoRows) {
+			respondWithError(w, http.StatusForbidden, "Couldn't reply to a user who blocked you", err)
+			return
+		}
+		respondWithError(w, http.StatusInternalServerError, "Couldn't create chirp", err)
+		return
+	}
+
+	if err := saveChirpEntities(req.Context(), qtx, chirp); err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't save chirp entities", err)
+		return
+	}
+
+	decision, err := cfg.screenChirp(req.Context(), qtx, chirp)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't screen chirp", err)
+		return
+	}
+
+	_, err = qtx.DeleteDraft(req.Context(), database.DeleteDraftParams{
+		ID:     draft.ID,
+		UserID: userID,
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't delete draft", err)
+		return
+	}
+
+	if err := tx.Commit(); err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't publish draft", err)
+		return
+	}
+	cfg.queueLinkPreviews(entities.URLs(chirp.Body))
+
+	jsonKeysChirp, err := cfg.renderChirp(req.Context(), chirp, userID)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't render chirp", err)
+		return
+	}
+
+	var moderation *ModerationDecision
+	if decision != nil {
+		res := renderModerationDecision(*decision)
+		moderation = &res
+	}
+
+	respondWithJSON(w, http.StatusCreated, response{
+		Chirp:       jsonKeysChirp,
+		MaskedWords: masked,
+		Moderation:  moderation,
+	})
+}
+
+func renderDraft(draft database.Draft) Draft {
+	res := Draft{
+		ID:        draft.ID,
+		CreatedAt: draft.CreatedAt,
+		UpdatedAt: draft.UpdatedAt,
+		UserID:    draft.UserID,
+		Body:      draft.Body,
+	}
+	if draft.ReplyTo.Valid {
+		res.ReplyTo = &draft.ReplyTo.UUID
+	}
+	if draft.QuoteOf.Valid {
+		res.QuoteOf = &draft.QuoteOf.UUID
+	}
+	return res
+}
+
+func toNullUUID(id *uuid.UUID) uuid.NullUUID {
+	if id == nil {
+		return uuid.NullUUID{}
+	}
+	return uuid.NullUUID{UUID: *id, Valid: true}
+}
@@ -0,0 +1,85 @@
+package main
+
+import (
+	"net/http"
+
+	"github.com/DanilShapilov/chirpy/internal/database"
+	"github.com/google/uuid"
+)
+
+func (cfg *apiConfig) handlerFollowCreate(w http.ResponseWriter, req *http.Request) {
+	followeeIDString := req.PathValue("userID")
+	followeeID, err := uuid.Parse(followeeIDString)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid user ID", err)
+		return
+	}
+
+	userID, ok := cfg.authenticate(w, req)
+	if !ok {
+		return
+	}
+
+	if followeeID == userID {
+		respondWithError(w, http.StatusBadRequest, "Couldn't follow yourself", nil)
+		return
+	}
+
+	followee, err := cfg.db.GetUser(req.Context(), followeeID)
+	if err != nil {
+		respondWithError(w, http.StatusNotFound, "Couldn't find user", err)
+		return
+	}
+
+	followed, err := cfg.db.FollowUser(req.Context(), database.FollowUserParams{
+		FollowerID: userID,
+		FolloweeID: followee.ID,
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't follow user", err)
+		return
+	}
+	// Nothing is inserted for a repeated follow or when the followee
+	// blocked the user, only the latter is an error.
+	if followed == 0 {
+		blocked, err := cfg.db.IsBlockedBy(req.Context(), database.IsBlockedByParams{
+			BlockerID: followee.ID,
+			BlockedID: userID,
+		})
+		if err != nil {
+			respondWithError(w, http.StatusInternalServerError, "Couldn't follow user", err)
+			return
+		}
+		if blocked {
+			respondWithError(w, http.StatusForbidden, "Couldn't follow a user who blocked you", nil)
+			return
+		}
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (cfg *apiConfig) handlerFollowDelete(w http.ResponseWriter, req *http.Request) {
+	followeeIDString := req.PathValue("userID")
+	followeeID, err := uuid.Parse(followeeIDString)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid user ID", err)
+		return
+	}
+
+	userID, ok := cfg.authenticate(w, req)
+	if !ok {
+		return
+	}
+
+	_, err = cfg.db.UnfollowUser(req.Context(), database.UnfollowUserParams{
+		FollowerID: userID,
+		FolloweeID: followeeID,
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't unfollow user", err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -7,7 +7,7 @@ import (
 	"time"
 
 	"github.com/DanilShapilov/chirpy/internal/auth"
-	"github.com/DanilShapilov/chirpy/internal/database"
+	"github.com/google/uuid"
 )
 
 func (cfg *apiConfig) handlerLogin(w http.ResponseWriter, req *http.Request) {
@@ -51,9 +51,18 @@ func (cfg *apiConfig) handlerLogin(w http.ResponseWriter, req *http.Request) {
 		return
 	}
 
-	accessToken, err := auth.MakeJWT(
+	suspended, err := cfg.db.IsUserSuspended(req.Context(), user.ID)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't check user", err)
+		return
+	}
+	if suspended {
+		respondWithError(w, http.StatusForbidden, "Account is suspended", nil)
+		return
+	}
+
+	accessToken, err := cfg.jwtKeys.MakeJWT(
 		user.ID,
-		cfg.jwtSecret,
 		time.Hour,
 	)
 	if err != nil {
@@ -66,7 +75,8 @@ func (cfg *apiConfig) handlerLogin(w http.ResponseWriter, req *http.Request) {
 		return
 	}
 
-	refreshToken, err := auth.MakeRefreshToken()
+	// Every login starts a new family of refresh tokens.
+	refreshToken, err := issueRefreshToken(req, cfg.db, user.ID, uuid.New())
 	if err != nil {
 		respondWithError(
 			w,
@@ -74,21 +84,7 @@ func (cfg *apiConfig) handlerLogin(w http.ResponseWriter, req *http.Request) {
 			"Couldn't create refresh token",
 			err,
 		)
-	}
-	refreshTokenExpTime := time.Hour * 24 * 60
-	_, err = cfg.db.CreateRefreshToken(req.Context(), database.CreateRefreshTokenParams{
-		Token:     refreshToken,
-		UserID:    user.ID,
-		ExpiresAt: time.Now().Add(refreshTokenExpTime).UTC(),
-	})
-
-	if err != nil {
-		respondWithError(
-			w,
-			http.StatusInternalServerError,
-			"Couldn't save refresh token",
-			err,
-		)
+		return
 	}
 
 	respondWithJSON(w, http.StatusOK, response{
@@ -0,0 +1,122 @@
+package main
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"unicode/utf8"
+
+	"github.com/DanilShapilov/chirpy/internal/database"
+	"github.com/google/uuid"
+)
+
+const maxAppealMessageLength = 500
+
+type ModerationDecisionsPage struct {
+	Decisions  []ModerationDecision `json:"decisions"`
+	NextCursor *string              `json:"next_cursor"`
+}
+
+// handlerDecisionsList lists the automatic decisions made about the
+// user's chirps, newest first.
+func (cfg *apiConfig) handlerDecisionsList(w http.ResponseWriter, req *http.Request) {
+	userID, ok := cfg.authenticate(w, req)
+	if !ok {
+		return
+	}
+
+	page, err := parsePageParams(req.URL.Query())
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, err.Error(), err)
+		return
+	}
+	if !page.Paginated {
+		page.Limit = defaultPageLimit
+	}
+
+	params := database.ListUserModerationDecisionsParams{
+		UserID: userID,
+		// Fetch one extra row to find out whether there is a next page.
+		Limit: page.Limit + 1,
+	}
+	if page.Cursor != nil {
+		params.CursorCreatedAt = sql.NullTime{Time: page.Cursor.CreatedAt, Valid: true}
+		params.CursorID = uuid.NullUUID{UUID: page.Cursor.ID, Valid: true}
+	}
+
+	rows, err := cfg.db.ListUserModerationDecisions(req.Context(), params)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't get decisions", err)
+		return
+	}
+
+	var nextCursor *string
+	if len(rows) > int(page.Limit) {
+		rows = rows[:page.Limit]
+		last := rows[len(rows)-1]
+		cursor := encodeCursor(last.CreatedAt, last.ID)
+		nextCursor = &cursor
+	}
+
+	decisions := make([]ModerationDecision, len(rows))
+	for i, row := range rows {
+		decisions[i] = renderModerationDecision(row)
+	}
+
+	respondWithJSON(w, http.StatusOK, ModerationDecisionsPage{
+		Decisions:  decisions,
+		NextCursor: nextCursor,
+	})
+}
+
+// handlerDecisionsAppeal lets the author ask a moderator to take
+// another look at a decision. Every decision can be appealed once.
+func (cfg *apiConfig) handlerDecisionsAppeal(w http.ResponseWriter, req *http.Request) {
+	type reqData struct {
+		Message string `json:"message"`
+	}
+
+	decisionIDString := req.PathValue("decisionID")
+	decisionID, err := uuid.Parse(decisionIDString)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid decision ID", err)
+		return
+	}
+
+	userID, ok := cfg.authenticate(w, req)
+	if !ok {
+		return
+	}
+
+	decoder := json.NewDecoder(req.Body)
+	params := reqData{}
+	err = decoder.Decode(&params)
+	defer req.Body.Close()
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't decode parameters", err)
+		return
+	}
+
+	if utf8.RuneCountInString(params.Message) > maxAppealMessageLength {
+		respondWithError(w, http.StatusBadRequest, "Message can be at most "+strconv.Itoa(maxAppealMessageLength)+" characters", nil)
+		return
+	}
+
+	decision, err := cfg.db.AppealModerationDecision(req.Context(), database.AppealModerationDecisionParams{
+		AppealMessage: params.Message,
+		ID:            decisionID,
+		UserID:        userID,
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			respondWithError(w, http.StatusNotFound, "Couldn't find a decision that can be appealed", err)
+			return
+		}
+		respondWithError(w, http.StatusInternalServerError, "Couldn't appeal decision", err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, renderModerationDecision(decision))
+}
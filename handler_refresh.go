@@ -1,15 +1,71 @@
 package main
 
 import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"io"
+	"log"
+	"net"
 	"net/http"
+	"strings"
 	"time"
 
 	"github.com/DanilShapilov/chirpy/internal/auth"
+	"github.com/DanilShapilov/chirpy/internal/database"
+	"github.com/google/uuid"
 )
 
+// refreshTokenTTL is how long a refresh token is valid. Every refresh
+// issues a new token, so a session in use doesn't expire.
+const refreshTokenTTL = 60 * 24 * time.Hour
+
+// maxUserAgentLength caps the user agent stored with a session.
+const maxUserAgentLength = 512
+
+// issueRefreshToken creates a new refresh token in the given family for
+// the client that sent req. Only its digest is stored.
+func issueRefreshToken(req *http.Request, q *database.Queries, userID, familyID uuid.UUID) (string, error) {
+	token, err := auth.MakeRefreshToken()
+	if err != nil {
+		return "", err
+	}
+	userAgent := req.UserAgent()
+	if len(userAgent) > maxUserAgentLength {
+		userAgent = strings.ToValidUTF8(userAgent[:maxUserAgentLength], "")
+	}
+	_, err = q.CreateRefreshToken(req.Context(), database.CreateRefreshTokenParams{
+		TokenHash: auth.HashRefreshToken(token),
+		UserID:    userID,
+		ExpiresAt: time.Now().Add(refreshTokenTTL).UTC(),
+		FamilyID:  familyID,
+		UserAgent: userAgent,
+		Ip:        clientIP(req),
+	})
+	if err != nil {
+		return "", err
+	}
+	return token, nil
+}
+
+// clientIP is the address the request came from. Chirpy is served
+// directly, so proxy headers aren't trusted.
+func clientIP(req *http.Request) string {
+	host, _, err := net.SplitHostPort(req.RemoteAddr)
+	if err != nil {
+		return req.RemoteAddr
+	}
+	return host
+}
+
+// handlerRefresh trades a refresh token for a new access JWT and a new
+// refresh token. The old refresh token is revoked. Presenting it again
+// means someone else got hold of it, so every token of its family is
+// revoked and both parties have to log in again.
 func (cfg *apiConfig) handlerRefresh(w http.ResponseWriter, req *http.Request) {
 	type response struct {
-		Token string `json:"token"`
+		Token        string `json:"token"`
+		RefreshToken string `json:"refresh_token"`
 	}
 
 	refreshToken, err := auth.GetBearerToken(req.Header)
@@ -22,18 +78,70 @@ func (cfg *apiConfig) handlerRefresh(w http.ResponseWriter, req *http.Request) {
 		)
 		return
 	}
-	user, err := cfg.db.GetUserFromRefreshToken(req.Context(), refreshToken)
+
+	tx, err := cfg.dbConn.BeginTx(req.Context(), nil)
 	if err != nil {
-		respondWithError(
-			w,
-			http.StatusUnauthorized,
-			"Couldn't get user for refresh token",
-			err,
+		respondWithError(w, http.StatusInternalServerError, "Couldn't refresh session", err)
+		return
+	}
+	defer tx.Rollback()
+	qtx := cfg.db.WithTx(tx)
+
+	// The row lock makes concurrent refreshes with the same token take
+	// turns, so only the first one rotates it.
+	token, err := qtx.GetRefreshTokenForUpdate(req.Context(), auth.HashRefreshToken(refreshToken))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			respondWithError(w, http.StatusUnauthorized, "Couldn't get user for refresh token", err)
+			return
+		}
+		respondWithError(w, http.StatusInternalServerError, "Couldn't refresh session", err)
+		return
+	}
+
+	if token.RotatedAt.Valid {
+		revoked, err := qtx.RevokeRefreshTokenFamily(req.Context(), token.FamilyID)
+		if err != nil {
+			respondWithError(w, http.StatusInternalServerError, "Couldn't revoke session", err)
+			return
+		}
+		if err := tx.Commit(); err != nil {
+			respondWithError(w, http.StatusInternalServerError, "Couldn't revoke session", err)
+			return
+		}
+		log.Printf(
+			"Possible refresh token theft: rotated token of user %s was reused, revoked %d tokens of family %s",
+			token.UserID, revoked, token.FamilyID,
 		)
+		respondWithError(w, http.StatusUnauthorized, "Refresh token was already used", nil)
+		return
+	}
+	if token.RevokedAt.Valid || !token.ExpiresAt.After(time.Now()) {
+		respondWithError(w, http.StatusUnauthorized, "Couldn't get user for refresh token", nil)
+		return
+	}
+
+	suspended, err := qtx.IsUserSuspended(req.Context(), token.UserID)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't check user", err)
+		return
+	}
+	if suspended {
+		respondWithError(w, http.StatusForbidden, "Account is suspended", nil)
+		return
+	}
+
+	if err := qtx.RotateRefreshToken(req.Context(), token.TokenHash); err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't rotate refresh token", err)
+		return
+	}
+	newRefreshToken, err := issueRefreshToken(req, qtx, token.UserID, token.FamilyID)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't create refresh token", err)
 		return
 	}
 
-	accessToken, err := auth.MakeJWT(user.ID, cfg.jwtSecret, time.Hour)
+	accessToken, err := cfg.jwtKeys.MakeJWT(token.UserID, time.Hour)
 	if err != nil {
 		respondWithError(
 			w,
@@ -43,12 +151,26 @@ func (cfg *apiConfig) handlerRefresh(w http.ResponseWriter, req *http.Request) {
 		)
 		return
 	}
+
+	if err := tx.Commit(); err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't refresh session", err)
+		return
+	}
+
 	respondWithJSON(w, http.StatusOK, response{
-		Token: accessToken,
+		Token:        accessToken,
+		RefreshToken: newRefreshToken,
 	})
 }
 
+// handlerRevoke logs a session out. The body may name the access token
+// the client holds, which is denylisted so it can't be used for the rest
+// of its hour.
 func (cfg *apiConfig) handlerRevoke(w http.ResponseWriter, req *http.Request) {
+	type parameters struct {
+		AccessToken string `json:"access_token"`
+	}
+
 	refreshToken, err := auth.GetBearerToken(req.Header)
 	if err != nil {
 		respondWithError(
@@ -59,7 +181,30 @@ func (cfg *apiConfig) handlerRevoke(w http.ResponseWriter, req *http.Request) {
 		)
 		return
 	}
-	_, err = cfg.db.RevokeRefreshToken(req.Context(), refreshToken)
+
+	defer req.Body.Close()
+	params := parameters{}
+	err = json.NewDecoder(req.Body).Decode(&params)
+	if err != nil && !errors.Is(err, io.EOF) {
+		respondWithError(w, http.StatusBadRequest, "Couldn't decode parameters", err)
+		return
+	}
+
+	var accessToken auth.AccessToken
+	if params.AccessToken != "" {
+		// Tokens that don't validate can't be used anyway.
+		accessToken, _ = cfg.jwtKeys.ParseJWT(params.AccessToken)
+	}
+
+	tx, err := cfg.dbConn.BeginTx(req.Context(), nil)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't revoke session", err)
+		return
+	}
+	defer tx.Rollback()
+	qtx := cfg.db.WithTx(tx)
+
+	revoked, err := qtx.RevokeRefreshToken(req.Context(), auth.HashRefreshToken(refreshToken))
 	if err != nil {
 		respondWithError(
 			w,
@@ -69,5 +214,22 @@ func (cfg *apiConfig) handlerRevoke(w http.ResponseWriter, req *http.Request) {
 		)
 		return
 	}
+	if accessToken.ID != uuid.Nil && accessToken.UserID != revoked.UserID {
+		respondWithError(w, http.StatusForbidden, "Access token belongs to another user", nil)
+		return
+	}
+
+	if err := tx.Commit(); err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't revoke session", err)
+		return
+	}
+
+	if accessToken.ID != uuid.Nil {
+		if err := cfg.accessTokens.revoke(req.Context(), accessToken); err != nil {
+			respondWithError(w, http.StatusInternalServerError, "Couldn't revoke access token", err)
+			return
+		}
+	}
+
 	w.WriteHeader(http.StatusNoContent)
 }
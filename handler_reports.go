@@ -0,0 +1,135 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"slices"
+	"strconv"
+	"time"
+	"unicode/utf8"
+
+	"github.com/DanilShapilov/chirpy/internal/database"
+	"github.com/google/uuid"
+)
+
+const maxReportDetailsLength = 500
+
+// reportReasons are the reason codes a report can be filed with.
+var reportReasons = []string{
+	"spam",
+	"harassment",
+	"hate",
+	"violence",
+	"sexual",
+	"misinformation",
+	"other",
+}
+
+type Report struct {
+	ID         uuid.UUID  `json:"id"`
+	CreatedAt  time.Time  `json:"created_at"`
+	ChirpID    uuid.UUID  `json:"chirp_id"`
+	ReporterID uuid.UUID  `json:"reporter_id"`
+	Reason     string     `json:"reason"`
+	Details    string     `json:"details"`
+	Status     string     `json:"status"`
+	ResolvedAt *time.Time `json:"resolved_at"`
+	ResolvedBy *uuid.UUID `json:"resolved_by"`
+}
+
+func renderReport(report database.Report) Report {
+	res := Report{
+		ID:         report.ID,
+		CreatedAt:  report.CreatedAt,
+		ChirpID:    report.ChirpID,
+		ReporterID: report.ReporterID,
+		Reason:     report.Reason,
+		Details:    report.Details,
+		Status:     report.Status,
+	}
+	if report.ResolvedAt.Valid {
+		res.ResolvedAt = &report.ResolvedAt.Time
+	}
+	if report.ResolvedBy.Valid {
+		res.ResolvedBy = &report.ResolvedBy.UUID
+	}
+	return res
+}
+
+func (cfg *apiConfig) handlerReportsCreate(w http.ResponseWriter, req *http.Request) {
+	type reqData struct {
+		Reason  string `json:"reason"`
+		Details string `json:"details"`
+	}
+
+	chirpIDString := req.PathValue("chirpID")
+	chirpID, err := uuid.Parse(chirpIDString)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid chirp ID", err)
+		return
+	}
+
+	userID, ok := cfg.authenticate(w, req)
+	if !ok {
+		return
+	}
+
+	decoder := json.NewDecoder(req.Body)
+	params := reqData{}
+	err = decoder.Decode(&params)
+	defer req.Body.Close()
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't decode parameters", err)
+		return
+	}
+
+	if !slices.Contains(reportReasons, params.Reason) {
+		respondWithError(w, http.StatusBadRequest, "Invalid report reason", nil)
+		return
+	}
+	if utf8.RuneCountInString(params.Details) > maxReportDetailsLength {
+		respondWithError(w, http.StatusBadRequest, "Details can be at most "+strconv.Itoa(maxReportDetailsLength)+" characters", nil)
+		return
+	}
+
+	chirp, err := cfg.db.GetChirp(req.Context(), chirpID)
+	if err != nil {
+		respondWithError(w, http.StatusNotFound, "Couldn't find chirp", err)
+		return
+	}
+
+	tx, err := cfg.dbConn.BeginTx(req.Context(), nil)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't create report", err)
+		return
+	}
+	defer tx.Rollback()
+	qtx := cfg.db.WithTx(tx)
+
+	report, err := qtx.CreateReport(req.Context(), database.CreateReportParams{
+		ChirpID:    chirp.ID,
+		ReporterID: userID,
+		Reason:     params.Reason,
+		Details:    params.Details,
+	})
+	if err != nil {
+		if isUniqueViolation(err) {
+			respondWithError(w, http.StatusConflict, "Chirp is already reported", err)
+			return
+		}
+		respondWithError(w, http.StatusInternalServerError, "Couldn't create report", err)
+		return
+	}
+
+	if _, err := cfg.checkReportThreshold(req.Context(), qtx, chirp.ID); err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't check reports", err)
+		return
+	}
+
+	if err := tx.Commit(); err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't create report", err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusCreated, renderReport(report))
+}
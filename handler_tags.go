@@ -0,0 +1,105 @@
+package main
+
+import (
+	"database/sql"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/DanilShapilov/chirpy/internal/database"
+	"github.com/google/uuid"
+)
+
+const (
+	defaultTrendingWindow = 24 * time.Hour
+	maxTrendingWindow     = 7 * 24 * time.Hour
+)
+
+type TrendingTag struct {
+	Tag        string `json:"tag"`
+	ChirpCount int64  `json:"chirp_count"`
+}
+
+func (cfg *apiConfig) handlerTagChirps(w http.ResponseWriter, req *http.Request) {
+	tag := strings.ToLower(strings.TrimPrefix(req.PathValue("tag"), "#"))
+	if tag == "" {
+		respondWithError(w, http.StatusBadRequest, "Tag couldn't be empty", nil)
+		return
+	}
+
+	page, err := parsePageParams(req.URL.Query())
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, err.Error(), err)
+		return
+	}
+	if !page.Paginated {
+		page.Limit = defaultPageLimit
+	}
+
+	viewerID := cfg.viewerID(req)
+	params := database.GetChirpsByTagParams{
+		Tag:      tag,
+		ViewerID: nullViewer(viewerID),
+		// Fetch one extra row to find out whether there is a next page.
+		Limit: page.Limit + 1,
+	}
+	if page.Cursor != nil {
+		params.CursorCreatedAt = sql.NullTime{Time: page.Cursor.CreatedAt, Valid: true}
+		params.CursorID = uuid.NullUUID{UUID: page.Cursor.ID, Valid: true}
+	}
+
+	chirps, err := cfg.db.GetChirpsByTag(req.Context(), params)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't get chirps from db", err)
+		return
+	}
+	chirps, nextCursor := trimChirpsPage(chirps, page.Limit)
+
+	res, err := cfg.renderChirps(req.Context(), chirps, viewerID)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't render chirps", err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, ChirpsPage{
+		Chirps:     res,
+		NextCursor: nextCursor,
+	})
+}
+
+func (cfg *apiConfig) handlerTagsTrending(w http.ResponseWriter, req *http.Request) {
+	window := defaultTrendingWindow
+	if windowStr := req.URL.Query().Get("window"); windowStr != "" {
+		var err error
+		window, err = time.ParseDuration(windowStr)
+		if err != nil || window <= 0 || window > maxTrendingWindow {
+			respondWithError(w, http.StatusBadRequest, "window must be a duration between 0 and "+maxTrendingWindow.String(), err)
+			return
+		}
+	}
+
+	limit, err := parseLimit(req.URL.Query())
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, err.Error(), err)
+		return
+	}
+
+	tags, err := cfg.db.GetTrendingTags(req.Context(), database.GetTrendingTagsParams{
+		Since: time.Now().Add(-window).UTC(),
+		Limit: limit,
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't get trending tags", err)
+		return
+	}
+
+	res := make([]TrendingTag, len(tags))
+	for i, tag := range tags {
+		res[i] = TrendingTag{
+			Tag:        tag.Tag,
+			ChirpCount: tag.ChirpCount,
+		}
+	}
+
+	respondWithJSON(w, http.StatusOK, res)
+}
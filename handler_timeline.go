@@ -0,0 +1,53 @@
+package main
+
+import (
+	"database/sql"
+	"net/http"
+
+	"github.com/DanilShapilov/chirpy/internal/database"
+	"github.com/google/uuid"
+)
+
+func (cfg *apiConfig) handlerTimeline(w http.ResponseWriter, req *http.Request) {
+	userID, ok := cfg.authenticate(w, req)
+	if !ok {
+		return
+	}
+
+	page, err := parsePageParams(req.URL.Query())
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, err.Error(), err)
+		return
+	}
+	if !page.Paginated {
+		page.Limit = defaultPageLimit
+	}
+
+	params := database.GetTimelineParams{
+		UserID: userID,
+		// Fetch one extra row to find out whether there is a next page.
+		Limit: page.Limit + 1,
+	}
+	if page.Cursor != nil {
+		params.CursorCreatedAt = sql.NullTime{Time: page.Cursor.CreatedAt, Valid: true}
+		params.CursorID = uuid.NullUUID{UUID: page.Cursor.ID, Valid: true}
+	}
+
+	chirps, err := cfg.db.GetTimeline(req.Context(), params)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't get timeline", err)
+		return
+	}
+	chirps, nextCursor := trimChirpsPage(chirps, page.Limit)
+
+	res, err := cfg.renderChirps(req.Context(), chirps, userID)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't render chirps", err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, ChirpsPage{
+		Chirps:     res,
+		NextCursor: nextCursor,
+	})
+}
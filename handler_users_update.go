@@ -18,31 +18,14 @@ func (cfg *apiConfig) handlerUsersUpdate(w http.ResponseWriter, req *http.Reques
 		User
 	}
 
-	token, err := auth.GetBearerToken(req.Header)
-	if err != nil {
-		respondWithError(
-			w,
-			http.StatusUnauthorized,
-			"Couldn't find JWT",
-			err,
-		)
-		return
-	}
-
-	userID, err := auth.ValidateJWT(token, cfg.jwtSecret)
-	if err != nil {
-		respondWithError(
-			w,
-			http.StatusUnauthorized,
-			"Couldn't validate JWT",
-			err,
-		)
+	userID, ok := cfg.authenticate(w, req)
+	if !ok {
 		return
 	}
 
 	decoder := json.NewDecoder(req.Body)
 	params := reqData{}
-	err = decoder.Decode(&params)
+	err := decoder.Decode(&params)
 
 	if err != nil {
 		log.Printf("Error decoding parameters: %s", err)
@@ -59,12 +42,28 @@ func (cfg *apiConfig) handlerUsersUpdate(w http.ResponseWriter, req *http.Reques
 		return
 	}
 
+	current, err := cfg.db.GetUser(req.Context(), userID)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't get user", err)
+		return
+	}
+	passwordChanged := auth.CheckPasswordHash(params.Password, current.HashedPassword) != nil
+
 	hashedPassword, err := auth.HashPassword(params.Password)
 	if err != nil {
 		respondWithError(w, http.StatusInternalServerError, err.Error(), err)
+		return
 	}
 
-	user, err := cfg.db.UpdateUser(req.Context(), database.UpdateUserParams{
+	tx, err := cfg.dbConn.BeginTx(req.Context(), nil)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't update user", err)
+		return
+	}
+	defer tx.Rollback()
+	qtx := cfg.db.WithTx(tx)
+
+	user, err := qtx.UpdateUser(req.Context(), database.UpdateUserParams{
 		ID:             userID,
 		Email:          params.Email,
 		HashedPassword: hashedPassword,
@@ -75,6 +74,26 @@ func (cfg *apiConfig) handlerUsersUpdate(w http.ResponseWriter, req *http.Reques
 		return
 	}
 
+	// A new password logs every session out, in case the old one leaked.
+	if passwordChanged {
+		if _, err := qtx.RevokeUserSessions(req.Context(), userID); err != nil {
+			respondWithError(w, http.StatusInternalServerError, "Couldn't revoke sessions", err)
+			return
+		}
+		if err := qtx.SetUserSessionsNotBefore(req.Context(), sessionsNotBeforeParams(userID)); err != nil {
+			respondWithError(w, http.StatusInternalServerError, "Couldn't revoke access tokens", err)
+			return
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't update user", err)
+		return
+	}
+	if passwordChanged {
+		cfg.accessTokens.forgetUser(userID)
+	}
+
 	respondWithJSON(w, http.StatusCreated, response{
 		User: User{
 			ID:          user.ID,
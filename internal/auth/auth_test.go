@@ -187,3 +187,22 @@ func TestGetBearerToken(t *testing.T) {
 	}
 
 }
+
+func TestHashRefreshToken(t *testing.T) {
+	token, err := MakeRefreshToken()
+	if err != nil {
+		t.Fatal(err)
+	}
+	hash := HashRefreshToken(token)
+	if hash == token || len(hash) != 64 {
+		t.Errorf("HashRefreshToken() = %q, want a hex SHA-256 digest", hash)
+	}
+	if HashRefreshToken(token) != hash {
+		t.Error("HashRefreshToken() isn't deterministic")
+	}
+	// echo -n "abc" | sha256sum
+	const want = "ba7816bf8f01cfea414140de5dae2223b00361a396177a9cb410ff61f20015ad"
+	if got := HashRefreshToken("abc"); got != want {
+		t.Errorf("HashRefreshToken(\"abc\") = %s, want %s", got, want)
+	}
+}
@@ -0,0 +1,338 @@
+package auth
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+)
+
+// minRSAKeyBits is the smallest RSA key accepted for signing JWTs.
+const minRSAKeyBits = 2048
+
+// tokenTimePrecision is the precision of iat and exp. iat is compared
+// with the time a user's sessions were revoked, and whole seconds would
+// leave a login right after the revocation indistinguishable from a
+// token issued right before it.
+const tokenTimePrecision = time.Microsecond
+
+func init() {
+	// The library reads NumericDates through a float64 and truncates
+	// them to TimePrecision, which can lose the last digit. Parsing at
+	// full precision and rounding in ParseJWT gets it back.
+	jwt.TimePrecision = time.Nanosecond
+}
+
+var (
+	ErrUnknownKey   = errors.New("unknown signing key")
+	ErrTokenRevoked = errors.New("token has been revoked")
+)
+
+// Key is a JWT key. Keys loaded from a private key can sign and verify,
+// keys loaded from a public key can only verify.
+type Key struct {
+	// ID is sent as the kid header of the JWTs the key signs.
+	ID        string
+	method    jwt.SigningMethod
+	signKey   any
+	verifyKey any
+}
+
+// HMACKey returns an HS256 key for the shared JWT_SECRET. Its ID is
+// empty because tokens signed before keys had IDs carry no kid.
+func HMACKey(secret string) *Key {
+	return &Key{
+		method:    jwt.SigningMethodHS256,
+		signKey:   []byte(secret),
+		verifyKey: []byte(secret),
+	}
+}
+
+// LoadKeyFile reads an RS256 or EdDSA key from a PEM file.
+func LoadKeyFile(path string) (*Key, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	key, err := ParseKeyPEM(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return key, nil
+}
+
+// ParseKeyPEM parses an RSA or Ed25519 key in PKCS #8, PKCS #1 or PKIX
+// form. The key ID is the RFC 7638 thumbprint of the public key, so it
+// stays the same wherever the key is loaded.
+func ParseKeyPEM(data []byte) (*Key, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("no PEM block found")
+	}
+
+	var parsed any
+	var err error
+	switch block.Type {
+	case "PRIVATE KEY":
+		parsed, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	case "RSA PRIVATE KEY":
+		parsed, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "PUBLIC KEY":
+		parsed, err = x509.ParsePKIXPublicKey(block.Bytes)
+	case "RSA PUBLIC KEY":
+		parsed, err = x509.ParsePKCS1PublicKey(block.Bytes)
+	default:
+		return nil, fmt.Errorf("unsupported PEM block %q", block.Type)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	key := &Key{}
+	switch k := parsed.(type) {
+	case *rsa.PrivateKey:
+		key.method, key.signKey, key.verifyKey = jwt.SigningMethodRS256, k, &k.PublicKey
+	case *rsa.PublicKey:
+		key.method, key.verifyKey = jwt.SigningMethodRS256, k
+	case ed25519.PrivateKey:
+		key.method, key.signKey, key.verifyKey = jwt.SigningMethodEdDSA, k, k.Public()
+	case ed25519.PublicKey:
+		key.method, key.verifyKey = jwt.SigningMethodEdDSA, k
+	default:
+		return nil, fmt.Errorf("unsupported key type %T", parsed)
+	}
+	if pub, ok := key.verifyKey.(*rsa.PublicKey); ok && pub.N.BitLen() < minRSAKeyBits {
+		return nil, fmt.Errorf("RSA keys must have at least %d bits", minRSAKeyBits)
+	}
+
+	jwk, _ := key.JWK()
+	key.ID = jwk.thumbprint()
+	return key, nil
+}
+
+// CanSign reports whether the key holds a private key or secret.
+func (k *Key) CanSign() bool {
+	return k.signKey != nil
+}
+
+// JWK is a public key in JSON Web Key form.
+type JWK struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	// RSA
+	N string `json:"n,omitempty"`
+	E string `json:"e,omitempty"`
+	// Ed25519
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+}
+
+// JWK returns the public half of the key. Shared secrets have none.
+func (k *Key) JWK() (JWK, bool) {
+	jwk := JWK{
+		Kid: k.ID,
+		Use: "sig",
+		Alg: k.method.Alg(),
+	}
+	switch pub := k.verifyKey.(type) {
+	case *rsa.PublicKey:
+		jwk.Kty = "RSA"
+		jwk.N = base64.RawURLEncoding.EncodeToString(pub.N.Bytes())
+		jwk.E = base64.RawURLEncoding.EncodeToString(big.NewInt(int64(pub.E)).Bytes())
+	case ed25519.PublicKey:
+		jwk.Kty = "OKP"
+		jwk.Crv = "Ed25519"
+		jwk.X = base64.RawURLEncoding.EncodeToString(pub)
+	default:
+		return JWK{}, false
+	}
+	return jwk, true
+}
+
+// thumbprint computes the RFC 7638 thumbprint: the SHA-256 of the
+// required members in lexicographic order.
+func (j JWK) thumbprint() string {
+	var members any
+	switch j.Kty {
+	case "RSA":
+		members = struct {
+			E   string `json:"e"`
+			Kty string `json:"kty"`
+			N   string `json:"n"`
+		}{j.E, j.Kty, j.N}
+	case "OKP":
+		members = struct {
+			Crv string `json:"crv"`
+			Kty string `json:"kty"`
+			X   string `json:"x"`
+		}{j.Crv, j.Kty, j.X}
+	}
+	data, _ := json.Marshal(members)
+	sum := sha256.Sum256(data)
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+// KeySet signs JWTs with one key and accepts JWTs signed by any of its
+// keys. To rotate keys, sign with the new key and keep the old one as a
+// verification key until the tokens it signed have expired.
+type KeySet struct {
+	signing *Key
+	// ordered holds the keys in the order they were given, signing
+	// key first.
+	ordered []*Key
+	keys    map[string]*Key
+
+	revocations RevocationChecker
+}
+
+// AccessToken is the content of a validated access JWT.
+type AccessToken struct {
+	// ID is the jti claim. Tokens issued before they had one have
+	// uuid.Nil, and can only be revoked through RevocationChecker's
+	// per-user checks.
+	ID        uuid.UUID
+	UserID    uuid.UUID
+	IssuedAt  time.Time
+	ExpiresAt time.Time
+}
+
+// RevocationChecker tells whether an access token was revoked before it
+// expired.
+type RevocationChecker interface {
+	IsRevoked(ctx context.Context, token AccessToken) (bool, error)
+}
+
+func NewKeySet(signing *Key, verification ...*Key) (*KeySet, error) {
+	if signing == nil || !signing.CanSign() {
+		return nil, errors.New("signing key must be a private key or secret")
+	}
+	ks := &KeySet{
+		signing: signing,
+		keys:    make(map[string]*Key, len(verification)+1),
+	}
+	for _, key := range append([]*Key{signing}, verification...) {
+		if _, ok := ks.keys[key.ID]; ok {
+			return nil, fmt.Errorf("duplicate key ID %q", key.ID)
+		}
+		ks.keys[key.ID] = key
+		ks.ordered = append(ks.ordered, key)
+	}
+	return ks, nil
+}
+
+// SetRevocationChecker makes ValidateJWT reject revoked tokens. Call it
+// before the set is used.
+func (ks *KeySet) SetRevocationChecker(rc RevocationChecker) {
+	ks.revocations = rc
+}
+
+func (ks *KeySet) MakeJWT(userID uuid.UUID, expiresIn time.Duration) (string, error) {
+	now := time.Now().UTC().Truncate(tokenTimePrecision)
+	token := jwt.NewWithClaims(ks.signing.method, jwt.RegisteredClaims{
+		ID:        uuid.NewString(),
+		Issuer:    string(TokenTypeAccess),
+		IssuedAt:  jwt.NewNumericDate(now),
+		ExpiresAt: jwt.NewNumericDate(now.Add(expiresIn)),
+		Subject:   userID.String(),
+	})
+	if ks.signing.ID != "" {
+		token.Header["kid"] = ks.signing.ID
+	}
+	return token.SignedString(ks.signing.signKey)
+}
+
+// ValidateJWT checks an access token and returns its user.
+func (ks *KeySet) ValidateJWT(ctx context.Context, tokenString string) (uuid.UUID, error) {
+	token, err := ks.ParseJWT(tokenString)
+	if err != nil {
+		return uuid.Nil, err
+	}
+	if ks.revocations != nil {
+		revoked, err := ks.revocations.IsRevoked(ctx, token)
+		if err != nil {
+			return uuid.Nil, fmt.Errorf("couldn't check revocation: %w", err)
+		}
+		if revoked {
+			return uuid.Nil, ErrTokenRevoked
+		}
+	}
+	return token.UserID, nil
+}
+
+// ParseJWT checks the signature and claims of an access token, but not
+// whether it was revoked.
+func (ks *KeySet) ParseJWT(tokenString string) (AccessToken, error) {
+	claims := &jwt.RegisteredClaims{}
+	_, err := jwt.ParseWithClaims(tokenString, claims, ks.keyFunc)
+	if err != nil {
+		return AccessToken{}, err
+	}
+
+	if claims.Issuer != string(TokenTypeAccess) {
+		return AccessToken{}, fmt.Errorf("invalid issuer")
+	}
+
+	userID, err := uuid.Parse(claims.Subject)
+	if err != nil {
+		return AccessToken{}, fmt.Errorf("invalid user ID: %w", err)
+	}
+	token := AccessToken{UserID: userID}
+	if claims.ID != "" {
+		token.ID, err = uuid.Parse(claims.ID)
+		if err != nil {
+			return AccessToken{}, fmt.Errorf("invalid token ID: %w", err)
+		}
+	}
+	if claims.IssuedAt != nil {
+		token.IssuedAt = claims.IssuedAt.Round(tokenTimePrecision)
+	}
+	if claims.ExpiresAt != nil {
+		token.ExpiresAt = claims.ExpiresAt.Round(tokenTimePrecision)
+	}
+	return token, nil
+}
+
+// keyFunc picks the key named by the kid header. The token's alg has to
+// match the key, so a public key can never be used as an HMAC secret.
+func (ks *KeySet) keyFunc(token *jwt.Token) (any, error) {
+	kid, _ := token.Header["kid"].(string)
+	key, ok := ks.keys[kid]
+	if !ok {
+		return nil, fmt.Errorf("%w: %q", ErrUnknownKey, kid)
+	}
+	if token.Method.Alg() != key.method.Alg() {
+		return nil, fmt.Errorf("unexpected signing method %s", token.Method.Alg())
+	}
+	return key.verifyKey, nil
+}
+
+// JWKS is the JSON Web Key Set served at /.well-known/jwks.json.
+type JWKS struct {
+	Keys []JWK `json:"keys"`
+}
+
+// JWKS returns the public keys of the set, signing key first. Shared
+// secrets are left out.
+func (ks *KeySet) JWKS() JWKS {
+	res := JWKS{Keys: []JWK{}}
+	for _, key := range ks.ordered {
+		if jwk, ok := key.JWK(); ok {
+			res.Keys = append(res.Keys, jwk)
+		}
+	}
+	return res
+}
@@ -0,0 +1,258 @@
+package auth
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+)
+
+func generateRSAKey(t *testing.T, bits int) (private, public []byte) {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, bits)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return encodeKeyPair(t, key, &key.PublicKey)
+}
+
+func generateEd25519Key(t *testing.T) (private, public []byte) {
+	t.Helper()
+	pub, key, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return encodeKeyPair(t, key, pub)
+}
+
+func encodeKeyPair(t *testing.T, private, public any) ([]byte, []byte) {
+	t.Helper()
+	privDER, err := x509.MarshalPKCS8PrivateKey(private)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pubDER, err := x509.MarshalPKIXPublicKey(public)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: privDER}),
+		pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: pubDER})
+}
+
+func mustParseKey(t *testing.T, data []byte) *Key {
+	t.Helper()
+	key, err := ParseKeyPEM(data)
+	if err != nil {
+		t.Fatalf("ParseKeyPEM() error = %v", err)
+	}
+	return key
+}
+
+func TestKeySetSignsAndVerifies(t *testing.T) {
+	rsaPriv, rsaPub := generateRSAKey(t, 2048)
+	edPriv, edPub := generateEd25519Key(t)
+
+	tests := []struct {
+		name    string
+		private []byte
+		public  []byte
+		alg     string
+	}{
+		{"RS256", rsaPriv, rsaPub, "RS256"},
+		{"EdDSA", edPriv, edPub, "EdDSA"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			signing := mustParseKey(t, tt.private)
+			public := mustParseKey(t, tt.public)
+			if signing.ID != public.ID {
+				t.Errorf("key IDs differ: %q != %q", signing.ID, public.ID)
+			}
+			if public.CanSign() {
+				t.Error("public key CanSign() = true")
+			}
+
+			ks, err := NewKeySet(signing)
+			if err != nil {
+				t.Fatal(err)
+			}
+			userID := uuid.New()
+			tokenString, err := ks.MakeJWT(userID, time.Hour)
+			if err != nil {
+				t.Fatalf("MakeJWT() error = %v", err)
+			}
+
+			token, _, err := jwt.NewParser().ParseUnverified(tokenString, &jwt.RegisteredClaims{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if token.Header["kid"] != signing.ID || token.Header["alg"] != tt.alg {
+				t.Errorf("header = %v, want kid %q and alg %q", token.Header, signing.ID, tt.alg)
+			}
+
+			// Another instance only holding the public key accepts it.
+			verifier := &KeySet{signing: signing, keys: map[string]*Key{public.ID: public}}
+			got, err := verifier.ValidateJWT(context.Background(), tokenString)
+			if err != nil {
+				t.Fatalf("ValidateJWT() error = %v", err)
+			}
+			if got != userID {
+				t.Errorf("ValidateJWT() = %v, want %v", got, userID)
+			}
+		})
+	}
+}
+
+func TestKeySetRotation(t *testing.T) {
+	oldPriv, oldPub := generateEd25519Key(t)
+	newPriv, _ := generateEd25519Key(t)
+	oldKey := mustParseKey(t, oldPriv)
+	newKey := mustParseKey(t, newPriv)
+	userID := uuid.New()
+
+	before, err := NewKeySet(oldKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	oldToken, err := before.MakeJWT(userID, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	during, err := NewKeySet(newKey, mustParseKey(t, oldPub))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := during.ValidateJWT(context.Background(), oldToken); err != nil {
+		t.Errorf("ValidateJWT(old token) error = %v", err)
+	}
+	if got := len(during.JWKS().Keys); got != 2 {
+		t.Errorf("len(JWKS().Keys) = %d, want 2", got)
+	}
+
+	after, err := NewKeySet(newKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := after.ValidateJWT(context.Background(), oldToken); !errors.Is(err, ErrUnknownKey) {
+		t.Errorf("ValidateJWT(old token) error = %v, want %v", err, ErrUnknownKey)
+	}
+}
+
+func TestKeySetRejectsAlgorithmConfusion(t *testing.T) {
+	priv, pub := generateRSAKey(t, 2048)
+	key := mustParseKey(t, priv)
+	ks, err := NewKeySet(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// An HS256 token using the published public key as its secret.
+	forged := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.RegisteredClaims{
+		Issuer:    string(TokenTypeAccess),
+		ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour)),
+		Subject:   uuid.NewString(),
+	})
+	forged.Header["kid"] = key.ID
+	tokenString, err := forged.SignedString(pub)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := ks.ValidateJWT(context.Background(), tokenString); err == nil {
+		t.Error("ValidateJWT() error = nil, want an error")
+	}
+}
+
+func TestKeySetLegacySecret(t *testing.T) {
+	priv, _ := generateEd25519Key(t)
+	ks, err := NewKeySet(mustParseKey(t, priv), HMACKey("MySecret"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	userID := uuid.New()
+	legacy, err := MakeJWT(userID, "MySecret", time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, err := ks.ValidateJWT(context.Background(), legacy); err != nil || got != userID {
+		t.Errorf("ValidateJWT(legacy) = %v, %v, want %v", got, err, userID)
+	}
+
+	for _, jwk := range ks.JWKS().Keys {
+		if jwk.Alg == "HS256" {
+			t.Error("JWKS() includes the shared secret")
+		}
+	}
+}
+
+func TestParseKeyPEM(t *testing.T) {
+	weak, _ := generateRSAKey(t, 1024)
+	if _, err := ParseKeyPEM(weak); err == nil {
+		t.Error("ParseKeyPEM(1024-bit RSA) error = nil, want an error")
+	}
+	if _, err := ParseKeyPEM([]byte("not a key")); err == nil {
+		t.Error("ParseKeyPEM(garbage) error = nil, want an error")
+	}
+
+	_, pub := generateEd25519Key(t)
+	if _, err := NewKeySet(mustParseKey(t, pub)); err == nil {
+		t.Error("NewKeySet(public key) error = nil, want an error")
+	}
+
+	priv, _ := generateEd25519Key(t)
+	key := mustParseKey(t, priv)
+	if _, err := NewKeySet(key, mustParseKey(t, priv)); err == nil {
+		t.Error("NewKeySet(duplicate keys) error = nil, want an error")
+	}
+}
+
+type revokedIDs map[uuid.UUID]bool
+
+func (r revokedIDs) IsRevoked(ctx context.Context, token AccessToken) (bool, error) {
+	return r[token.ID], nil
+}
+
+func TestKeySetRevocation(t *testing.T) {
+	ks, err := NewKeySet(HMACKey("MySecret"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	revoked := revokedIDs{}
+	ks.SetRevocationChecker(revoked)
+
+	userID := uuid.New()
+	before := time.Now()
+	tokenString, err := ks.MakeJWT(userID, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	token, err := ks.ParseJWT(tokenString)
+	if err != nil {
+		t.Fatalf("ParseJWT() error = %v", err)
+	}
+	if token.ID == uuid.Nil || token.UserID != userID || token.ExpiresAt.IsZero() {
+		t.Errorf("ParseJWT() = %+v, want a jti, the user and an expiry", token)
+	}
+	if token.IssuedAt.Before(before.Truncate(tokenTimePrecision)) {
+		t.Errorf("ParseJWT() IssuedAt = %v, want at least %v", token.IssuedAt, before.Truncate(tokenTimePrecision))
+	}
+
+	if _, err := ks.ValidateJWT(context.Background(), tokenString); err != nil {
+		t.Fatalf("ValidateJWT() error = %v", err)
+	}
+	revoked[token.ID] = true
+	if _, err := ks.ValidateJWT(context.Background(), tokenString); !errors.Is(err, ErrTokenRevoked) {
+		t.Errorf("ValidateJWT() error = %v, want %v", err, ErrTokenRevoked)
+	}
+}
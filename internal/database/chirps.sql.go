@@ -7,29 +7,137 @@ package database
 
 import (
 	"context"
+	"database/sql"
+	"time"
 
 	"github.com/google/uuid"
+	"github.com/lib/pq"
 )
 
+const countChirpReplies = `-- name: CountChirpReplies :many
+SELECT reply_to, COUNT(*) AS reply_count FROM chirps
+WHERE reply_to = ANY($1::uuid[])
+AND deleted_at IS NULL
+AND publish_at IS NULL
+AND NOT EXISTS (
+    SELECT 1 FROM blocks
+    WHERE (blocks.blocker_id = $2::uuid AND blocks.blocked_id = chirps.user_id)
+    OR (blocks.blocker_id = chirps.user_id AND blocks.blocked_id = $2::uuid)
+)
+GROUP BY reply_to
+`
+
+type CountChirpRepliesParams struct {
+	ChirpIds []uuid.UUID
+	ViewerID uuid.NullUUID
+}
+
+type CountChirpRepliesRow struct {
+	ReplyTo    uuid.NullUUID
+	ReplyCount int64
+}
+
+func (q *Queries) CountChirpReplies(ctx context.Context, arg CountChirpRepliesParams) ([]CountChirpRepliesRow, error) {
+	rows, err := q.db.QueryContext(ctx, countChirpReplies, pq.Array(arg.ChirpIds), arg.ViewerID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []CountChirpRepliesRow
+	for rows.Next() {
+		var i CountChirpRepliesRow
+		if err := rows.Scan(
+			&i.ReplyTo,
+			&i.ReplyCount,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const createChirp = `-- name: CreateChirp :one
-INSERT INTO chirps (id, created_at, updated_at, body, user_id)
+INSERT INTO chirps (id, created_at, updated_at, body, user_id, reply_to, quote_of, publish_at)
+SELECT
+    gen_random_uuid(),
+    NOW(),
+    NOW(),
+    $1::text,
+    $2::uuid,
+    $3::uuid,
+    $4::uuid,
+    $5::timestamp
+WHERE NOT EXISTS (
+    SELECT 1 FROM chirps AS parent
+    JOIN blocks ON blocks.blocker_id = parent.user_id
+    WHERE parent.id = $3 AND blocks.blocked_id = $2
+)
+RETURNING id, created_at, updated_at, body, user_id, reply_to, search_vector, deleted_at, rechirp_of, quote_of, publish_at, hidden_at
+`
+
+type CreateChirpParams struct {
+	Body      string
+	UserID    uuid.UUID
+	ReplyTo   uuid.NullUUID
+	QuoteOf   uuid.NullUUID
+	PublishAt sql.NullTime
+}
+
+func (q *Queries) CreateChirp(ctx context.Context, arg CreateChirpParams) (Chirp, error) {
+	row := q.db.QueryRowContext(ctx, createChirp,
+		arg.Body,
+		arg.UserID,
+		arg.ReplyTo,
+		arg.QuoteOf,
+		arg.PublishAt,
+	)
+	var i Chirp
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Body,
+		&i.UserID,
+		&i.ReplyTo,
+		&i.SearchVector,
+		&i.DeletedAt,
+		&i.RechirpOf,
+		&i.QuoteOf,
+		&i.PublishAt,
+		&i.HiddenAt,
+	)
+	return i, err
+}
+
+const createRechirp = `-- name: CreateRechirp :one
+INSERT INTO chirps (id, created_at, updated_at, body, user_id, rechirp_of)
 VALUES (
     gen_random_uuid(),
     NOW(),
     NOW(),
+    '',
     $1,
     $2
 )
-RETURNING id, created_at, updated_at, body, user_id
+ON CONFLICT (user_id, rechirp_of) WHERE rechirp_of IS NOT NULL AND deleted_at IS NULL
+DO NOTHING
+RETURNING id, created_at, updated_at, body, user_id, reply_to, search_vector, deleted_at, rechirp_of, quote_of, publish_at, hidden_at
 `
 
-type CreateChirpParams struct {
-	Body   string
-	UserID uuid.UUID
+type CreateRechirpParams struct {
+	UserID    uuid.UUID
+	RechirpOf uuid.NullUUID
 }
 
-func (q *Queries) CreateChirp(ctx context.Context, arg CreateChirpParams) (Chirp, error) {
-	row := q.db.QueryRowContext(ctx, createChirp, arg.Body, arg.UserID)
+func (q *Queries) CreateRechirp(ctx context.Context, arg CreateRechirpParams) (Chirp, error) {
+	row := q.db.QueryRowContext(ctx, createRechirp, arg.UserID, arg.RechirpOf)
 	var i Chirp
 	err := row.Scan(
 		&i.ID,
@@ -37,21 +145,50 @@ func (q *Queries) CreateChirp(ctx context.Context, arg CreateChirpParams) (Chirp
 		&i.UpdatedAt,
 		&i.Body,
 		&i.UserID,
+		&i.ReplyTo,
+		&i.SearchVector,
+		&i.DeletedAt,
+		&i.RechirpOf,
+		&i.QuoteOf,
+		&i.PublishAt,
+		&i.HiddenAt,
 	)
 	return i, err
 }
 
-const deleteChirp = `-- name: DeleteChirp :exec
-DELETE FROM chirps WHERE id = $1
+const deleteScheduledChirp = `-- name: DeleteScheduledChirp :one
+DELETE FROM chirps
+WHERE id = $1 AND user_id = $2 AND publish_at IS NOT NULL
+RETURNING id, created_at, updated_at, body, user_id, reply_to, search_vector, deleted_at, rechirp_of, quote_of, publish_at, hidden_at
 `
 
-func (q *Queries) DeleteChirp(ctx context.Context, id uuid.UUID) error {
-	_, err := q.db.ExecContext(ctx, deleteChirp, id)
-	return err
+type DeleteScheduledChirpParams struct {
+	ID     uuid.UUID
+	UserID uuid.UUID
+}
+
+func (q *Queries) DeleteScheduledChirp(ctx context.Context, arg DeleteScheduledChirpParams) (Chirp, error) {
+	row := q.db.QueryRowContext(ctx, deleteScheduledChirp, arg.ID, arg.UserID)
+	var i Chirp
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Body,
+		&i.UserID,
+		&i.ReplyTo,
+		&i.SearchVector,
+		&i.DeletedAt,
+		&i.RechirpOf,
+		&i.QuoteOf,
+		&i.PublishAt,
+		&i.HiddenAt,
+	)
+	return i, err
 }
 
 const getChirp = `-- name: GetChirp :one
-SELECT id, created_at, updated_at, body, user_id FROM chirps WHERE id = $1
+SELECT id, created_at, updated_at, body, user_id, reply_to, search_vector, deleted_at, rechirp_of, quote_of, publish_at, hidden_at FROM chirps WHERE id = $1 AND deleted_at IS NULL AND publish_at IS NULL
 `
 
 func (q *Queries) GetChirp(ctx context.Context, id uuid.UUID) (Chirp, error) {
@@ -63,16 +200,497 @@ func (q *Queries) GetChirp(ctx context.Context, id uuid.UUID) (Chirp, error) {
 		&i.UpdatedAt,
 		&i.Body,
 		&i.UserID,
+		&i.ReplyTo,
+		&i.SearchVector,
+		&i.DeletedAt,
+		&i.RechirpOf,
+		&i.QuoteOf,
+		&i.PublishAt,
+		&i.HiddenAt,
+	)
+	return i, err
+}
+
+const getChirpForUpdate = `-- name: GetChirpForUpdate :one
+SELECT id, created_at, updated_at, body, user_id, reply_to, search_vector, deleted_at, rechirp_of, quote_of, publish_at, hidden_at FROM chirps
+WHERE id = $1 AND deleted_at IS NULL AND publish_at IS NULL
+FOR UPDATE
+`
+
+func (q *Queries) GetChirpForUpdate(ctx context.Context, id uuid.UUID) (Chirp, error) {
+	row := q.db.QueryRowContext(ctx, getChirpForUpdate, id)
+	var i Chirp
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Body,
+		&i.UserID,
+		&i.ReplyTo,
+		&i.SearchVector,
+		&i.DeletedAt,
+		&i.RechirpOf,
+		&i.QuoteOf,
+		&i.PublishAt,
+		&i.HiddenAt,
+	)
+	return i, err
+}
+
+const getChirpReplyTree = `-- name: GetChirpReplyTree :many
+WITH RECURSIVE thread AS (
+    SELECT chirps.id, chirps.created_at, chirps.updated_at, chirps.body, chirps.user_id, chirps.reply_to, chirps.search_vector, chirps.deleted_at, chirps.rechirp_of, chirps.quote_of, chirps.publish_at, chirps.hidden_at, 0 AS depth FROM chirps
+    WHERE chirps.id = $1
+    AND chirps.deleted_at IS NULL AND chirps.publish_at IS NULL
+    AND NOT EXISTS (
+        SELECT 1 FROM blocks
+        WHERE (blocks.blocker_id = $2::uuid AND blocks.blocked_id = chirps.user_id)
+        OR (blocks.blocker_id = chirps.user_id AND blocks.blocked_id = $2::uuid)
+    )
+    UNION ALL
+    SELECT chirps.id, chirps.created_at, chirps.updated_at, chirps.body, chirps.user_id, chirps.reply_to, chirps.search_vector, chirps.deleted_at, chirps.rechirp_of, chirps.quote_of, chirps.publish_at, chirps.hidden_at, thread.depth + 1 FROM chirps
+    JOIN thread ON chirps.reply_to = thread.id
+    WHERE thread.depth < $3::int
+    AND chirps.deleted_at IS NULL AND chirps.publish_at IS NULL
+    AND NOT EXISTS (
+        SELECT 1 FROM blocks
+        WHERE (blocks.blocker_id = $2::uuid AND blocks.blocked_id = chirps.user_id)
+        OR (blocks.blocker_id = chirps.user_id AND blocks.blocked_id = $2::uuid)
+    )
+)
+SELECT id, created_at, updated_at, body, user_id, reply_to, search_vector, deleted_at, rechirp_of, quote_of, publish_at, hidden_at, depth FROM thread
+ORDER BY depth ASC, created_at ASC, id ASC
+`
+
+type GetChirpReplyTreeParams struct {
+	RootID   uuid.UUID
+	MaxDepth int32
+	ViewerID uuid.NullUUID
+}
+
+type GetChirpReplyTreeRow struct {
+	ID           uuid.UUID
+	CreatedAt    time.Time
+	UpdatedAt    time.Time
+	Body         string
+	UserID       uuid.UUID
+	ReplyTo      uuid.NullUUID
+	SearchVector interface{}
+	DeletedAt    sql.NullTime
+	RechirpOf    uuid.NullUUID
+	QuoteOf      uuid.NullUUID
+	PublishAt    sql.NullTime
+	HiddenAt     sql.NullTime
+	Depth        int32
+}
+
+func (q *Queries) GetChirpReplyTree(ctx context.Context, arg GetChirpReplyTreeParams) ([]GetChirpReplyTreeRow, error) {
+	rows, err := q.db.QueryContext(ctx, getChirpReplyTree, arg.RootID, arg.MaxDepth, arg.ViewerID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetChirpReplyTreeRow
+	for rows.Next() {
+		var i GetChirpReplyTreeRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Body,
+			&i.UserID,
+			&i.ReplyTo,
+			&i.SearchVector,
+			&i.DeletedAt,
+			&i.RechirpOf,
+			&i.QuoteOf,
+			&i.PublishAt,
+			&i.HiddenAt,
+			&i.Depth,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getChirpThreadRoot = `-- name: GetChirpThreadRoot :one
+WITH RECURSIVE ancestors AS (
+    SELECT chirps.id, chirps.reply_to, 0 AS depth FROM chirps
+    WHERE chirps.id = $1
+    AND chirps.deleted_at IS NULL AND chirps.publish_at IS NULL
+    UNION ALL
+    SELECT chirps.id, chirps.reply_to, ancestors.depth + 1 FROM chirps
+    JOIN ancestors ON chirps.id = ancestors.reply_to
+    WHERE chirps.deleted_at IS NULL AND chirps.publish_at IS NULL
+)
+SELECT id FROM ancestors
+ORDER BY depth DESC
+LIMIT 1
+`
+
+func (q *Queries) GetChirpThreadRoot(ctx context.Context, chirpID uuid.UUID) (uuid.UUID, error) {
+	row := q.db.QueryRowContext(ctx, getChirpThreadRoot, chirpID)
+	var id uuid.UUID
+	err := row.Scan(&id)
+	return id, err
+}
+
+const getChirpsByIDs = `-- name: GetChirpsByIDs :many
+SELECT id, created_at, updated_at, body, user_id, reply_to, search_vector, deleted_at, rechirp_of, quote_of, publish_at, hidden_at FROM chirps
+WHERE id = ANY($1::uuid[])
+AND deleted_at IS NULL
+AND publish_at IS NULL
+AND NOT EXISTS (
+    SELECT 1 FROM blocks
+    WHERE (blocks.blocker_id = $2::uuid AND blocks.blocked_id = chirps.user_id)
+    OR (blocks.blocker_id = chirps.user_id AND blocks.blocked_id = $2::uuid)
+)
+`
+
+type GetChirpsByIDsParams struct {
+	Ids      []uuid.UUID
+	ViewerID uuid.NullUUID
+}
+
+func (q *Queries) GetChirpsByIDs(ctx context.Context, arg GetChirpsByIDsParams) ([]Chirp, error) {
+	rows, err := q.db.QueryContext(ctx, getChirpsByIDs, pq.Array(arg.Ids), arg.ViewerID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Chirp
+	for rows.Next() {
+		var i Chirp
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Body,
+			&i.UserID,
+			&i.ReplyTo,
+			&i.SearchVector,
+			&i.DeletedAt,
+			&i.RechirpOf,
+			&i.QuoteOf,
+			&i.PublishAt,
+			&i.HiddenAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getDeletedChirp = `-- name: GetDeletedChirp :one
+SELECT id, created_at, updated_at, body, user_id, reply_to, search_vector, deleted_at, rechirp_of, quote_of, publish_at, hidden_at FROM chirps WHERE id = $1 AND deleted_at IS NOT NULL AND hidden_at IS NULL
+`
+
+func (q *Queries) GetDeletedChirp(ctx context.Context, id uuid.UUID) (Chirp, error) {
+	row := q.db.QueryRowContext(ctx, getDeletedChirp, id)
+	var i Chirp
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Body,
+		&i.UserID,
+		&i.ReplyTo,
+		&i.SearchVector,
+		&i.DeletedAt,
+		&i.RechirpOf,
+		&i.QuoteOf,
+		&i.PublishAt,
+		&i.HiddenAt,
+	)
+	return i, err
+}
+
+const getRecentChirpsByUser = `-- name: GetRecentChirpsByUser :many
+SELECT body, created_at FROM chirps
+WHERE user_id = $1
+AND created_at >= $2
+AND created_at <= $3
+AND id <> $4
+AND rechirp_of IS NULL
+ORDER BY created_at DESC
+LIMIT 500
+`
+
+type GetRecentChirpsByUserParams struct {
+	UserID    uuid.UUID
+	Since     time.Time
+	Until     time.Time
+	ExcludeID uuid.UUID
+}
+
+type GetRecentChirpsByUserRow struct {
+	Body      string
+	CreatedAt time.Time
+}
+
+func (q *Queries) GetRecentChirpsByUser(ctx context.Context, arg GetRecentChirpsByUserParams) ([]GetRecentChirpsByUserRow, error) {
+	rows, err := q.db.QueryContext(ctx, getRecentChirpsByUser,
+		arg.UserID,
+		arg.Since,
+		arg.Until,
+		arg.ExcludeID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetRecentChirpsByUserRow
+	for rows.Next() {
+		var i GetRecentChirpsByUserRow
+		if err := rows.Scan(
+			&i.Body,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getTimeline = `-- name: GetTimeline :many
+SELECT chirps.id, chirps.created_at, chirps.updated_at, chirps.body, chirps.user_id, chirps.reply_to, chirps.search_vector, chirps.deleted_at, chirps.rechirp_of, chirps.quote_of, chirps.publish_at, chirps.hidden_at FROM chirps
+JOIN (
+    SELECT followee_id AS author_id FROM follows WHERE follower_id = $1
+    UNION ALL
+    SELECT $1::uuid
+) AS authors ON chirps.user_id = authors.author_id
+WHERE chirps.deleted_at IS NULL
+AND chirps.publish_at IS NULL
+AND NOT EXISTS (
+    SELECT 1 FROM blocks
+    WHERE (blocks.blocker_id = $1 AND blocks.blocked_id = chirps.user_id)
+    OR (blocks.blocker_id = chirps.user_id AND blocks.blocked_id = $1)
+)
+AND NOT EXISTS (
+    SELECT 1 FROM mutes
+    WHERE mutes.muter_id = $1 AND mutes.muted_id = chirps.user_id
+)
+AND (
+    $2::timestamp IS NULL
+    OR (chirps.created_at, chirps.id) < ($2, $3::uuid)
+)
+ORDER BY chirps.created_at DESC, chirps.id DESC
+LIMIT $4
+`
+
+type GetTimelineParams struct {
+	UserID          uuid.UUID
+	CursorCreatedAt sql.NullTime
+	CursorID        uuid.NullUUID
+	Limit           int32
+}
+
+func (q *Queries) GetTimeline(ctx context.Context, arg GetTimelineParams) ([]Chirp, error) {
+	rows, err := q.db.QueryContext(ctx, getTimeline,
+		arg.UserID,
+		arg.CursorCreatedAt,
+		arg.CursorID,
+		arg.Limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Chirp
+	for rows.Next() {
+		var i Chirp
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Body,
+			&i.UserID,
+			&i.ReplyTo,
+			&i.SearchVector,
+			&i.DeletedAt,
+			&i.RechirpOf,
+			&i.QuoteOf,
+			&i.PublishAt,
+			&i.HiddenAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const hideChirp = `-- name: HideChirp :one
+UPDATE chirps
+SET deleted_at = COALESCE(deleted_at, NOW()), hidden_at = NOW()
+WHERE id = $1 AND hidden_at IS NULL
+RETURNING id, created_at, updated_at, body, user_id, reply_to, search_vector, deleted_at, rechirp_of, quote_of, publish_at, hidden_at
+`
+
+func (q *Queries) HideChirp(ctx context.Context, id uuid.UUID) (Chirp, error) {
+	row := q.db.QueryRowContext(ctx, hideChirp, id)
+	var i Chirp
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Body,
+		&i.UserID,
+		&i.ReplyTo,
+		&i.SearchVector,
+		&i.DeletedAt,
+		&i.RechirpOf,
+		&i.QuoteOf,
+		&i.PublishAt,
+		&i.HiddenAt,
 	)
 	return i, err
 }
 
-const getChirps = `-- name: GetChirps :many
-SELECT id, created_at, updated_at, body, user_id FROM chirps ORDER BY created_at ASC
+const listChirps = `-- name: ListChirps :many
+SELECT id, created_at, updated_at, body, user_id, reply_to, search_vector, deleted_at, rechirp_of, quote_of, publish_at, hidden_at FROM chirps
+WHERE deleted_at IS NULL
+AND publish_at IS NULL
+AND ($1::uuid IS NULL OR user_id = $1)
+AND NOT EXISTS (
+    SELECT 1 FROM blocks
+    WHERE (blocks.blocker_id = $2::uuid AND blocks.blocked_id = chirps.user_id)
+    OR (blocks.blocker_id = chirps.user_id AND blocks.blocked_id = $2::uuid)
+)
+AND (
+    $1 IS NOT NULL
+    OR NOT EXISTS (
+        SELECT 1 FROM mutes
+        WHERE mutes.muter_id = $2 AND mutes.muted_id = chirps.user_id
+    )
+)
+AND (
+    $3::timestamp IS NULL
+    OR (created_at, id) > ($3, $4::uuid)
+)
+ORDER BY created_at ASC, id ASC
+LIMIT $5
+`
+
+type ListChirpsParams struct {
+	AuthorID        uuid.NullUUID
+	ViewerID        uuid.NullUUID
+	CursorCreatedAt sql.NullTime
+	CursorID        uuid.NullUUID
+	Limit           sql.NullInt32
+}
+
+func (q *Queries) ListChirps(ctx context.Context, arg ListChirpsParams) ([]Chirp, error) {
+	rows, err := q.db.QueryContext(ctx, listChirps,
+		arg.AuthorID,
+		arg.ViewerID,
+		arg.CursorCreatedAt,
+		arg.CursorID,
+		arg.Limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Chirp
+	for rows.Next() {
+		var i Chirp
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Body,
+			&i.UserID,
+			&i.ReplyTo,
+			&i.SearchVector,
+			&i.DeletedAt,
+			&i.RechirpOf,
+			&i.QuoteOf,
+			&i.PublishAt,
+			&i.HiddenAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listChirpsDesc = `-- name: ListChirpsDesc :many
+SELECT id, created_at, updated_at, body, user_id, reply_to, search_vector, deleted_at, rechirp_of, quote_of, publish_at, hidden_at FROM chirps
+WHERE deleted_at IS NULL
+AND publish_at IS NULL
+AND ($1::uuid IS NULL OR user_id = $1)
+AND NOT EXISTS (
+    SELECT 1 FROM blocks
+    WHERE (blocks.blocker_id = $2::uuid AND blocks.blocked_id = chirps.user_id)
+    OR (blocks.blocker_id = chirps.user_id AND blocks.blocked_id = $2::uuid)
+)
+AND (
+    $1 IS NOT NULL
+    OR NOT EXISTS (
+        SELECT 1 FROM mutes
+        WHERE mutes.muter_id = $2 AND mutes.muted_id = chirps.user_id
+    )
+)
+AND (
+    $3::timestamp IS NULL
+    OR (created_at, id) < ($3, $4::uuid)
+)
+ORDER BY created_at DESC, id DESC
+LIMIT $5
 `
 
-func (q *Queries) GetChirps(ctx context.Context) ([]Chirp, error) {
-	rows, err := q.db.QueryContext(ctx, getChirps)
+type ListChirpsDescParams struct {
+	AuthorID        uuid.NullUUID
+	ViewerID        uuid.NullUUID
+	CursorCreatedAt sql.NullTime
+	CursorID        uuid.NullUUID
+	Limit           sql.NullInt32
+}
+
+func (q *Queries) ListChirpsDesc(ctx context.Context, arg ListChirpsDescParams) ([]Chirp, error) {
+	rows, err := q.db.QueryContext(ctx, listChirpsDesc,
+		arg.AuthorID,
+		arg.ViewerID,
+		arg.CursorCreatedAt,
+		arg.CursorID,
+		arg.Limit,
+	)
 	if err != nil {
 		return nil, err
 	}
@@ -86,6 +704,13 @@ func (q *Queries) GetChirps(ctx context.Context) ([]Chirp, error) {
 			&i.UpdatedAt,
 			&i.Body,
 			&i.UserID,
+			&i.ReplyTo,
+			&i.SearchVector,
+			&i.DeletedAt,
+			&i.RechirpOf,
+			&i.QuoteOf,
+			&i.PublishAt,
+			&i.HiddenAt,
 		); err != nil {
 			return nil, err
 		}
@@ -100,14 +725,67 @@ func (q *Queries) GetChirps(ctx context.Context) ([]Chirp, error) {
 	return items, nil
 }
 
-const getChirpsByAuthor = `-- name: GetChirpsByAuthor :many
-SELECT id, created_at, updated_at, body, user_id FROM chirps 
+const listScheduledChirps = `-- name: ListScheduledChirps :many
+SELECT id, created_at, updated_at, body, user_id, reply_to, search_vector, deleted_at, rechirp_of, quote_of, publish_at, hidden_at FROM chirps
 WHERE user_id = $1
-ORDER BY created_at ASC
+AND deleted_at IS NULL
+AND publish_at IS NOT NULL
+ORDER BY publish_at ASC, id ASC
+`
+
+func (q *Queries) ListScheduledChirps(ctx context.Context, userID uuid.UUID) ([]Chirp, error) {
+	rows, err := q.db.QueryContext(ctx, listScheduledChirps, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Chirp
+	for rows.Next() {
+		var i Chirp
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Body,
+			&i.UserID,
+			&i.ReplyTo,
+			&i.SearchVector,
+			&i.DeletedAt,
+			&i.RechirpOf,
+			&i.QuoteOf,
+			&i.PublishAt,
+			&i.HiddenAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const publishDueChirps = `-- name: PublishDueChirps :many
+WITH due AS (
+    SELECT id FROM chirps
+    WHERE publish_at <= NOW() AND deleted_at IS NULL
+    ORDER BY publish_at ASC
+    LIMIT $1
+    FOR UPDATE SKIP LOCKED
+)
+UPDATE chirps
+SET publish_at = NULL, created_at = NOW(), updated_at = NOW()
+FROM due
+WHERE chirps.id = due.id
+RETURNING chirps.id, chirps.created_at, chirps.updated_at, chirps.body, chirps.user_id, chirps.reply_to, chirps.search_vector, chirps.deleted_at, chirps.rechirp_of, chirps.quote_of, chirps.publish_at, chirps.hidden_at
 `
 
-func (q *Queries) GetChirpsByAuthor(ctx context.Context, userID uuid.UUID) ([]Chirp, error) {
-	rows, err := q.db.QueryContext(ctx, getChirpsByAuthor, userID)
+func (q *Queries) PublishDueChirps(ctx context.Context, limit int32) ([]Chirp, error) {
+	rows, err := q.db.QueryContext(ctx, publishDueChirps, limit)
 	if err != nil {
 		return nil, err
 	}
@@ -121,6 +799,148 @@ func (q *Queries) GetChirpsByAuthor(ctx context.Context, userID uuid.UUID) ([]Ch
 			&i.UpdatedAt,
 			&i.Body,
 			&i.UserID,
+			&i.ReplyTo,
+			&i.SearchVector,
+			&i.DeletedAt,
+			&i.RechirpOf,
+			&i.QuoteOf,
+			&i.PublishAt,
+			&i.HiddenAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const purgeDeletedChirps = `-- name: PurgeDeletedChirps :execrows
+DELETE FROM chirps WHERE deleted_at < $1 AND hidden_at IS NULL
+`
+
+func (q *Queries) PurgeDeletedChirps(ctx context.Context, deletedBefore sql.NullTime) (int64, error) {
+	result, err := q.db.ExecContext(ctx, purgeDeletedChirps, deletedBefore)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const restoreChirp = `-- name: RestoreChirp :one
+UPDATE chirps
+SET deleted_at = NULL
+WHERE id = $1 AND deleted_at > $2 AND hidden_at IS NULL
+RETURNING id, created_at, updated_at, body, user_id, reply_to, search_vector, deleted_at, rechirp_of, quote_of, publish_at, hidden_at
+`
+
+type RestoreChirpParams struct {
+	ID           uuid.UUID
+	DeletedAfter sql.NullTime
+}
+
+func (q *Queries) RestoreChirp(ctx context.Context, arg RestoreChirpParams) (Chirp, error) {
+	row := q.db.QueryRowContext(ctx, restoreChirp, arg.ID, arg.DeletedAfter)
+	var i Chirp
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Body,
+		&i.UserID,
+		&i.ReplyTo,
+		&i.SearchVector,
+		&i.DeletedAt,
+		&i.RechirpOf,
+		&i.QuoteOf,
+		&i.PublishAt,
+		&i.HiddenAt,
+	)
+	return i, err
+}
+
+const searchChirps = `-- name: SearchChirps :many
+SELECT
+    chirps.id, chirps.created_at, chirps.updated_at, chirps.body, chirps.user_id, chirps.reply_to, chirps.search_vector, chirps.deleted_at, chirps.rechirp_of, chirps.quote_of, chirps.publish_at, chirps.hidden_at,
+    (
+        ts_rank(chirps.search_vector, query)
+        * CASE WHEN users.is_chirpy_red THEN $1::real ELSE 1 END
+    )::real AS rank,
+    ts_headline(
+        'english',
+        translate(chirps.body, chr(2) || chr(3), ''),
+        query,
+        'StartSel=' || chr(2) || ', StopSel=' || chr(3) || ', HighlightAll=true'
+    )::text AS highlight
+FROM chirps
+JOIN users ON users.id = chirps.user_id
+CROSS JOIN websearch_to_tsquery('english', $2) AS query
+WHERE chirps.search_vector @@ query
+AND chirps.deleted_at IS NULL
+AND chirps.publish_at IS NULL
+AND ($3::uuid IS NULL OR chirps.user_id = $3)
+AND NOT EXISTS (
+    SELECT 1 FROM blocks
+    WHERE (blocks.blocker_id = $4::uuid AND blocks.blocked_id = chirps.user_id)
+    OR (blocks.blocker_id = chirps.user_id AND blocks.blocked_id = $4::uuid)
+)
+AND NOT EXISTS (
+    SELECT 1 FROM mutes
+    WHERE mutes.muter_id = $4 AND mutes.muted_id = chirps.user_id
+)
+ORDER BY rank DESC, chirps.created_at DESC, chirps.id DESC
+LIMIT $5
+`
+
+type SearchChirpsParams struct {
+	RedBoost float32
+	Query    string
+	AuthorID uuid.NullUUID
+	ViewerID uuid.NullUUID
+	Limit    int32
+}
+
+type SearchChirpsRow struct {
+	Chirp     Chirp
+	Rank      float32
+	Highlight string
+}
+
+func (q *Queries) SearchChirps(ctx context.Context, arg SearchChirpsParams) ([]SearchChirpsRow, error) {
+	rows, err := q.db.QueryContext(ctx, searchChirps,
+		arg.RedBoost,
+		arg.Query,
+		arg.AuthorID,
+		arg.ViewerID,
+		arg.Limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []SearchChirpsRow
+	for rows.Next() {
+		var i SearchChirpsRow
+		if err := rows.Scan(
+			&i.Chirp.ID,
+			&i.Chirp.CreatedAt,
+			&i.Chirp.UpdatedAt,
+			&i.Chirp.Body,
+			&i.Chirp.UserID,
+			&i.Chirp.ReplyTo,
+			&i.Chirp.SearchVector,
+			&i.Chirp.DeletedAt,
+			&i.Chirp.RechirpOf,
+			&i.Chirp.QuoteOf,
+			&i.Chirp.PublishAt,
+			&i.Chirp.HiddenAt,
+			&i.Rank,
+			&i.Highlight,
 		); err != nil {
 			return nil, err
 		}
@@ -134,3 +954,73 @@ func (q *Queries) GetChirpsByAuthor(ctx context.Context, userID uuid.UUID) ([]Ch
 	}
 	return items, nil
 }
+
+const softDeleteChirp = `-- name: SoftDeleteChirp :exec
+UPDATE chirps
+SET deleted_at = NOW()
+WHERE id = $1 AND deleted_at IS NULL
+`
+
+func (q *Queries) SoftDeleteChirp(ctx context.Context, id uuid.UUID) error {
+	_, err := q.db.ExecContext(ctx, softDeleteChirp, id)
+	return err
+}
+
+const unhideChirp = `-- name: UnhideChirp :one
+UPDATE chirps
+SET deleted_at = CASE WHEN deleted_at = hidden_at THEN NULL ELSE deleted_at END, hidden_at = NULL
+WHERE id = $1 AND hidden_at IS NOT NULL
+RETURNING id, created_at, updated_at, body, user_id, reply_to, search_vector, deleted_at, rechirp_of, quote_of, publish_at, hidden_at
+`
+
+func (q *Queries) UnhideChirp(ctx context.Context, id uuid.UUID) (Chirp, error) {
+	row := q.db.QueryRowContext(ctx, unhideChirp, id)
+	var i Chirp
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Body,
+		&i.UserID,
+		&i.ReplyTo,
+		&i.SearchVector,
+		&i.DeletedAt,
+		&i.RechirpOf,
+		&i.QuoteOf,
+		&i.PublishAt,
+		&i.HiddenAt,
+	)
+	return i, err
+}
+
+const updateChirpBody = `-- name: UpdateChirpBody :one
+UPDATE chirps
+SET body = $1, updated_at = NOW()
+WHERE id = $2
+RETURNING id, created_at, updated_at, body, user_id, reply_to, search_vector, deleted_at, rechirp_of, quote_of, publish_at, hidden_at
+`
+
+type UpdateChirpBodyParams struct {
+	Body string
+	ID   uuid.UUID
+}
+
+func (q *Queries) UpdateChirpBody(ctx context.Context, arg UpdateChirpBodyParams) (Chirp, error) {
+	row := q.db.QueryRowContext(ctx, updateChirpBody, arg.Body, arg.ID)
+	var i Chirp
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Body,
+		&i.UserID,
+		&i.ReplyTo,
+		&i.SearchVector,
+		&i.DeletedAt,
+		&i.RechirpOf,
+		&i.QuoteOf,
+		&i.PublishAt,
+		&i.HiddenAt,
+	)
+	return i, err
+}
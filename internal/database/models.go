@@ -11,28 +11,212 @@ import (
 	"github.com/google/uuid"
 )
 
+type BannedWord struct {
+	Word      string
+	CreatedAt time.Time
+}
+
+type Block struct {
+	BlockerID uuid.UUID
+	BlockedID uuid.UUID
+	CreatedAt time.Time
+}
+
+type Bookmark struct {
+	UserID       uuid.UUID
+	ChirpID      uuid.UUID
+	CollectionID uuid.NullUUID
+	CreatedAt    time.Time
+}
+
+type BookmarkCollection struct {
+	ID        uuid.UUID
+	CreatedAt time.Time
+	UpdatedAt time.Time
+	UserID    uuid.UUID
+	Name      string
+}
+
 type Chirp struct {
+	ID           uuid.UUID
+	CreatedAt    time.Time
+	UpdatedAt    time.Time
+	Body         string
+	UserID       uuid.UUID
+	ReplyTo      uuid.NullUUID
+	SearchVector interface{}
+	DeletedAt    sql.NullTime
+	RechirpOf    uuid.NullUUID
+	QuoteOf      uuid.NullUUID
+	PublishAt    sql.NullTime
+	HiddenAt     sql.NullTime
+}
+
+type ChirpLike struct {
+	UserID    uuid.UUID
+	ChirpID   uuid.UUID
+	CreatedAt time.Time
+}
+
+type ChirpLink struct {
+	ChirpID  uuid.UUID
+	Position int32
+	Url      string
+}
+
+type ChirpMedium struct {
+	ID              uuid.UUID
+	ChirpID         uuid.UUID
+	Position        int32
+	StorageKey      string
+	ThumbnailKey    string
+	ContentType     string
+	Width           int32
+	Height          int32
+	ThumbnailWidth  int32
+	ThumbnailHeight int32
+	AltText         string
+	CreatedAt       time.Time
+}
+
+type ChirpMention struct {
+	ChirpID uuid.UUID
+	UserID  uuid.UUID
+}
+
+type ChirpRevision struct {
+	ID         uuid.UUID
+	ChirpID    uuid.UUID
+	CreatedAt  time.Time
+	ReplacedAt time.Time
+	Body       string
+}
+
+type ChirpTag struct {
+	ChirpID   uuid.UUID
+	Tag       string
+	CreatedAt time.Time
+}
+
+type Draft struct {
 	ID        uuid.UUID
 	CreatedAt time.Time
 	UpdatedAt time.Time
+	UserID    uuid.UUID
 	Body      string
+	ReplyTo   uuid.NullUUID
+	QuoteOf   uuid.NullUUID
+}
+
+type Follow struct {
+	FollowerID uuid.UUID
+	FolloweeID uuid.UUID
+	CreatedAt  time.Time
+}
+
+type LinkPreview struct {
+	Url         string
+	FetchedAt   time.Time
+	Ok          bool
+	Title       string
+	Description string
+	ImageUrl    string
+	SiteName    string
+}
+
+type ModerationAction struct {
+	ID          uuid.UUID
+	CreatedAt   time.Time
+	ModeratorID uuid.UUID
+	Action      string
+	ChirpID     uuid.NullUUID
+	UserID      uuid.NullUUID
+	ReportID    uuid.NullUUID
+	Reason      string
+}
+
+type ModerationDecision struct {
+	ID            uuid.UUID
+	CreatedAt     time.Time
+	ChirpID       uuid.UUID
+	UserID        uuid.UUID
+	Rule          string
+	Reason        string
+	AppealStatus  string
+	AppealMessage string
+	AppealedAt    sql.NullTime
+	ResolvedAt    sql.NullTime
+	ResolvedBy    uuid.NullUUID
+}
+
+type Mute struct {
+	MuterID   uuid.UUID
+	MutedID   uuid.UUID
+	CreatedAt time.Time
+}
+
+type Poll struct {
+	ChirpID   uuid.UUID
+	CreatedAt time.Time
+	ExpiresAt time.Time
+}
+
+type PollOption struct {
+	ChirpID  uuid.UUID
+	Position int32
+	Text     string
+}
+
+type PollVote struct {
+	ChirpID   uuid.UUID
 	UserID    uuid.UUID
+	Position  int32
+	CreatedAt time.Time
 }
 
 type RefreshToken struct {
-	Token     string
+	TokenHash  string
+	UserID     uuid.UUID
+	CreatedAt  time.Time
+	UpdatedAt  time.Time
+	ExpiresAt  time.Time
+	RevokedAt  sql.NullTime
+	FamilyID   uuid.UUID
+	RotatedAt  sql.NullTime
+	UserAgent  string
+	Ip         string
+	LastUsedAt time.Time
+}
+
+type Report struct {
+	ID         uuid.UUID
+	CreatedAt  time.Time
+	ChirpID    uuid.UUID
+	ReporterID uuid.UUID
+	Reason     string
+	Details    string
+	Status     string
+	ResolvedAt sql.NullTime
+	ResolvedBy uuid.NullUUID
+}
+
+type RevokedAccessToken struct {
+	Jti       uuid.UUID
 	UserID    uuid.UUID
-	CreatedAt time.Time
-	UpdatedAt time.Time
+	RevokedAt time.Time
 	ExpiresAt time.Time
-	RevokedAt sql.NullTime
 }
 
 type User struct {
-	ID             uuid.UUID
-	CreatedAt      time.Time
-	UpdatedAt      time.Time
-	Email          string
-	HashedPassword string
-	IsChirpyRed    bool
+	ID                uuid.UUID
+	CreatedAt         time.Time
+	UpdatedAt         time.Time
+	Email             string
+	HashedPassword    string
+	IsChirpyRed       bool
+	IsAdmin           bool
+	SuspendedAt       sql.NullTime
+	SuspendedUntil    sql.NullTime
+	SuspensionReason  string
+	SessionsNotBefore sql.NullTime
 }
@@ -7,8 +7,10 @@ package database
 
 import (
 	"context"
+	"database/sql"
 
 	"github.com/google/uuid"
+	"github.com/lib/pq"
 )
 
 const createUser = `-- name: CreateUser :one
@@ -20,7 +22,7 @@ VALUES (
     $1,
     $2
 )
-RETURNING id, created_at, updated_at, email, hashed_password, is_chirpy_red
+RETURNING id, created_at, updated_at, email, hashed_password, is_chirpy_red, is_admin, suspended_at, suspended_until, suspension_reason, sessions_not_before
 `
 
 type CreateUserParams struct {
@@ -38,12 +40,41 @@ func (q *Queries) CreateUser(ctx context.Context, arg CreateUserParams) (User, e
 		&i.Email,
 		&i.HashedPassword,
 		&i.IsChirpyRed,
+		&i.IsAdmin,
+		&i.SuspendedAt,
+		&i.SuspendedUntil,
+		&i.SuspensionReason,
+		&i.SessionsNotBefore,
+	)
+	return i, err
+}
+
+const getUser = `-- name: GetUser :one
+SELECT id, created_at, updated_at, email, hashed_password, is_chirpy_red, is_admin, suspended_at, suspended_until, suspension_reason, sessions_not_before FROM users
+WHERE id = $1
+`
+
+func (q *Queries) GetUser(ctx context.Context, id uuid.UUID) (User, error) {
+	row := q.db.QueryRowContext(ctx, getUser, id)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Email,
+		&i.HashedPassword,
+		&i.IsChirpyRed,
+		&i.IsAdmin,
+		&i.SuspendedAt,
+		&i.SuspendedUntil,
+		&i.SuspensionReason,
+		&i.SessionsNotBefore,
 	)
 	return i, err
 }
 
 const getUserByEmail = `-- name: GetUserByEmail :one
-SELECT id, created_at, updated_at, email, hashed_password, is_chirpy_red FROM users
+SELECT id, created_at, updated_at, email, hashed_password, is_chirpy_red, is_admin, suspended_at, suspended_until, suspension_reason, sessions_not_before FROM users
 WHERE email = $1
 `
 
@@ -57,6 +88,153 @@ func (q *Queries) GetUserByEmail(ctx context.Context, email string) (User, error
 		&i.Email,
 		&i.HashedPassword,
 		&i.IsChirpyRed,
+		&i.IsAdmin,
+		&i.SuspendedAt,
+		&i.SuspendedUntil,
+		&i.SuspensionReason,
+		&i.SessionsNotBefore,
+	)
+	return i, err
+}
+
+const getUserSessionsNotBefore = `-- name: GetUserSessionsNotBefore :one
+SELECT sessions_not_before FROM users
+WHERE id = $1
+`
+
+func (q *Queries) GetUserSessionsNotBefore(ctx context.Context, id uuid.UUID) (sql.NullTime, error) {
+	row := q.db.QueryRowContext(ctx, getUserSessionsNotBefore, id)
+	var sessionsNotBefore sql.NullTime
+	err := row.Scan(&sessionsNotBefore)
+	return sessionsNotBefore, err
+}
+
+const getUsersByEmails = `-- name: GetUsersByEmails :many
+SELECT id, created_at, updated_at, email, hashed_password, is_chirpy_red, is_admin, suspended_at, suspended_until, suspension_reason, sessions_not_before FROM users
+WHERE lower(email) = ANY($1::text[])
+`
+
+func (q *Queries) GetUsersByEmails(ctx context.Context, emails []string) ([]User, error) {
+	rows, err := q.db.QueryContext(ctx, getUsersByEmails, pq.Array(emails))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []User
+	for rows.Next() {
+		var i User
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Email,
+			&i.HashedPassword,
+			&i.IsChirpyRed,
+			&i.IsAdmin,
+			&i.SuspendedAt,
+			&i.SuspendedUntil,
+			&i.SuspensionReason,
+			&i.SessionsNotBefore,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const isUserSuspended = `-- name: IsUserSuspended :one
+SELECT EXISTS (
+    SELECT 1 FROM users
+    WHERE id = $1
+    AND suspended_at IS NOT NULL
+    AND (suspended_until IS NULL OR suspended_until > NOW())
+)
+`
+
+func (q *Queries) IsUserSuspended(ctx context.Context, id uuid.UUID) (bool, error) {
+	row := q.db.QueryRowContext(ctx, isUserSuspended, id)
+	var exists bool
+	err := row.Scan(&exists)
+	return exists, err
+}
+
+const setUserSessionsNotBefore = `-- name: SetUserSessionsNotBefore :exec
+UPDATE users
+SET sessions_not_before = $1, updated_at = NOW()
+WHERE id = $2
+`
+
+type SetUserSessionsNotBeforeParams struct {
+	SessionsNotBefore sql.NullTime
+	ID                uuid.UUID
+}
+
+func (q *Queries) SetUserSessionsNotBefore(ctx context.Context, arg SetUserSessionsNotBeforeParams) error {
+	_, err := q.db.ExecContext(ctx, setUserSessionsNotBefore, arg.SessionsNotBefore, arg.ID)
+	return err
+}
+
+const suspendUser = `-- name: SuspendUser :one
+UPDATE users
+SET suspended_at = NOW(), suspended_until = $1, suspension_reason = $2, updated_at = NOW()
+WHERE id = $3
+RETURNING id, created_at, updated_at, email, hashed_password, is_chirpy_red, is_admin, suspended_at, suspended_until, suspension_reason, sessions_not_before
+`
+
+type SuspendUserParams struct {
+	SuspendedUntil   sql.NullTime
+	SuspensionReason string
+	ID               uuid.UUID
+}
+
+func (q *Queries) SuspendUser(ctx context.Context, arg SuspendUserParams) (User, error) {
+	row := q.db.QueryRowContext(ctx, suspendUser, arg.SuspendedUntil, arg.SuspensionReason, arg.ID)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Email,
+		&i.HashedPassword,
+		&i.IsChirpyRed,
+		&i.IsAdmin,
+		&i.SuspendedAt,
+		&i.SuspendedUntil,
+		&i.SuspensionReason,
+		&i.SessionsNotBefore,
+	)
+	return i, err
+}
+
+const unsuspendUser = `-- name: UnsuspendUser :one
+UPDATE users
+SET suspended_at = NULL, suspended_until = NULL, suspension_reason = '', updated_at = NOW()
+WHERE id = $1 AND suspended_at IS NOT NULL
+RETURNING id, created_at, updated_at, email, hashed_password, is_chirpy_red, is_admin, suspended_at, suspended_until, suspension_reason, sessions_not_before
+`
+
+func (q *Queries) UnsuspendUser(ctx context.Context, id uuid.UUID) (User, error) {
+	row := q.db.QueryRowContext(ctx, unsuspendUser, id)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Email,
+		&i.HashedPassword,
+		&i.IsChirpyRed,
+		&i.IsAdmin,
+		&i.SuspendedAt,
+		&i.SuspendedUntil,
+		&i.SuspensionReason,
+		&i.SessionsNotBefore,
 	)
 	return i, err
 }
@@ -65,7 +243,7 @@ const updateUser = `-- name: UpdateUser :one
 UPDATE users
 SET email = $1, hashed_password = $2, updated_at = NOW()
 WHERE id = $3
-RETURNING id, created_at, updated_at, email, hashed_password, is_chirpy_red
+RETURNING id, created_at, updated_at, email, hashed_password, is_chirpy_red, is_admin, suspended_at, suspended_until, suspension_reason, sessions_not_before
 `
 
 type UpdateUserParams struct {
@@ -84,6 +262,11 @@ func (q *Queries) UpdateUser(ctx context.Context, arg UpdateUserParams) (User, e
 		&i.Email,
 		&i.HashedPassword,
 		&i.IsChirpyRed,
+		&i.IsAdmin,
+		&i.SuspendedAt,
+		&i.SuspendedUntil,
+		&i.SuspensionReason,
+		&i.SessionsNotBefore,
 	)
 	return i, err
 }
@@ -92,7 +275,7 @@ const upgradeUserToChirpyRed = `-- name: UpgradeUserToChirpyRed :one
 UPDATE users
 SET is_chirpy_red = true, updated_at = NOW()
 WHERE id = $1
-RETURNING id, created_at, updated_at, email, hashed_password, is_chirpy_red
+RETURNING id, created_at, updated_at, email, hashed_password, is_chirpy_red, is_admin, suspended_at, suspended_until, suspension_reason, sessions_not_before
 `
 
 func (q *Queries) UpgradeUserToChirpyRed(ctx context.Context, id uuid.UUID) (User, error) {
@@ -105,6 +288,11 @@ func (q *Queries) UpgradeUserToChirpyRed(ctx context.Context, id uuid.UUID) (Use
 		&i.Email,
 		&i.HashedPassword,
 		&i.IsChirpyRed,
+		&i.IsAdmin,
+		&i.SuspendedAt,
+		&i.SuspendedUntil,
+		&i.SuspensionReason,
+		&i.SessionsNotBefore,
 	)
 	return i, err
 }
@@ -0,0 +1,196 @@
+// Package entities finds structured entities such as hashtags, mentions
+// and links inside chirp bodies.
+package entities
+
+import (
+	"regexp"
+	"slices"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+type Kind string
+
+const (
+	KindHashtag Kind = "hashtag"
+	KindMention Kind = "mention"
+	KindURL     Kind = "url"
+)
+
+const (
+	// URLLength is how many characters every link counts for against the
+	// chirp length limit, however long it is.
+	URLLength = 23
+	// MaxURLLength is the longest link, in bytes, a chirp may contain.
+	MaxURLLength = 2048
+	// maxDisplayURLLength is the length links are shortened to for
+	// display, including the ellipsis.
+	maxDisplayURLLength = 25
+)
+
+// Entity is a single match in a chirp body. Offset and Length are counted
+// in runes so clients don't have to care about the UTF-8 encoding.
+type Entity struct {
+	Kind   Kind
+	Offset int
+	Length int
+	// Text is the matched text including the leading # or @.
+	Text string
+	// Value is the normalized form: the lowercase tag without '#', the
+	// lowercase email of the mentioned user without '@', or the URL.
+	Value string
+	// Display is the shortened form of a URL shown in place of Text.
+	// It is empty for other kinds.
+	Display string
+}
+
+var (
+	hashtagRegex = regexp.MustCompile(`(?:^|[^\p{L}\p{N}_&#])(#[\p{L}\p{N}_]+)`)
+	// Users don't have handles, so mentions address them by email,
+	// e.g. "@walt@breakingbad.com".
+	mentionRegex = regexp.MustCompile(`(?:^|[^\p{L}\p{N}_.@])(@[A-Za-z0-9._%+\-]+@[A-Za-z0-9\-]+(?:\.[A-Za-z0-9\-]+)+)`)
+	urlRegex     = regexp.MustCompile(`(?i)\bhttps?://[^\s<>"]+`)
+)
+
+// Extract returns the hashtags, mentions and links in body ordered by
+// offset. Hashtags and mentions inside links are part of the link.
+func Extract(body string) []Entity {
+	var res []Entity
+	links := URLIndexes(body)
+	for _, link := range links {
+		res = append(res, newURLEntity(body, link[0], link[1]))
+	}
+	inLink := func(start int) bool {
+		return slices.ContainsFunc(links, func(link [2]int) bool {
+			return start >= link[0] && start < link[1]
+		})
+	}
+
+	for _, m := range hashtagRegex.FindAllStringSubmatchIndex(body, -1) {
+		text := body[m[2]:m[3]]
+		if !strings.ContainsFunc(text[1:], func(r rune) bool { return !unicode.IsDigit(r) }) {
+			// "#1" is a number, not a tag.
+			continue
+		}
+		if inLink(m[2]) {
+			continue
+		}
+		res = append(res, newEntity(body, KindHashtag, m[2], m[3]))
+	}
+	for _, m := range mentionRegex.FindAllStringSubmatchIndex(body, -1) {
+		if inLink(m[2]) {
+			continue
+		}
+		res = append(res, newEntity(body, KindMention, m[2], m[3]))
+	}
+	slices.SortFunc(res, func(a, b Entity) int {
+		return a.Offset - b.Offset
+	})
+	return res
+}
+
+// Hashtags returns the distinct normalized tags in body.
+func Hashtags(body string) []string {
+	return values(Extract(body), KindHashtag)
+}
+
+// Mentions returns the distinct normalized emails mentioned in body.
+func Mentions(body string) []string {
+	return values(Extract(body), KindMention)
+}
+
+// URLIndexes returns the byte offsets of the start and end of every link
+// in body, like regexp.FindAllStringIndex.
+func URLIndexes(body string) [][2]int {
+	var res [][2]int
+	for _, m := range urlRegex.FindAllStringIndex(body, -1) {
+		res = append(res, [2]int{m[0], m[0] + len(trimURL(body[m[0]:m[1]]))})
+	}
+	return res
+}
+
+// URLs returns the distinct links in body in the order they appear.
+func URLs(body string) []string {
+	return values(Extract(body), KindURL)
+}
+
+// Length is the length of body counted against the chirp length limit:
+// characters, except that every link counts as URLLength.
+func Length(body string) int {
+	length := utf8.RuneCountInString(body)
+	for _, e := range Extract(body) {
+		if e.Kind == KindURL {
+			length += URLLength - e.Length
+		}
+	}
+	return length
+}
+
+func newEntity(body string, kind Kind, start, end int) Entity {
+	text := body[start:end]
+	return Entity{
+		Kind:   kind,
+		Offset: utf8.RuneCountInString(body[:start]),
+		Length: utf8.RuneCountInString(text),
+		Text:   text,
+		Value:  strings.ToLower(text[1:]),
+	}
+}
+
+func newURLEntity(body string, start, end int) Entity {
+	text := body[start:end]
+	return Entity{
+		Kind:    KindURL,
+		Offset:  utf8.RuneCountInString(body[:start]),
+		Length:  utf8.RuneCountInString(text),
+		Text:    text,
+		Value:   text,
+		Display: displayURL(text),
+	}
+}
+
+// trimURL drops trailing punctuation that ends the sentence rather than
+// the link. A closing parenthesis is kept when it has a match inside the
+// link, as in "https://en.wikipedia.org/wiki/Go_(game)".
+func trimURL(url string) string {
+	for url != "" {
+		last := url[len(url)-1]
+		if last == ')' && strings.Count(url, "(") >= strings.Count(url, ")") {
+			return url
+		}
+		if !strings.ContainsRune(".,:;!?'\")]}", rune(last)) {
+			return url
+		}
+		url = url[:len(url)-1]
+	}
+	return url
+}
+
+// displayURL drops the scheme and "www." and shortens the rest to
+// maxDisplayURLLength.
+func displayURL(url string) string {
+	_, display, _ := strings.Cut(url, "://")
+	display = strings.TrimPrefix(display, "www.")
+	if utf8.RuneCountInString(display) <= maxDisplayURLLength {
+		return display
+	}
+	runes := []rune(display)
+	return string(runes[:maxDisplayURLLength-1]) + "…"
+}
+
+func values(entities []Entity, kind Kind) []string {
+	seen := make(map[string]struct{})
+	var res []string
+	for _, e := range entities {
+		if e.Kind != kind {
+			continue
+		}
+		if _, ok := seen[e.Value]; ok {
+			continue
+		}
+		seen[e.Value] = struct{}{}
+		res = append(res, e.Value)
+	}
+	return res
+}
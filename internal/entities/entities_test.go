@@ -0,0 +1,133 @@
+package entities
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestExtract(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+		want []Entity
+	}{
+		{
+			name: "No entities",
+			body: "I had something interesting for breakfast",
+			want: nil,
+		},
+		{
+			name: "Hashtag and mention",
+			body: "#Breakfast with @walt@breakingbad.com",
+			want: []Entity{
+				{Kind: KindHashtag, Offset: 0, Length: 10, Text: "#Breakfast", Value: "breakfast"},
+				{Kind: KindMention, Offset: 16, Length: 21, Text: "@walt@breakingbad.com", Value: "walt@breakingbad.com"},
+			},
+		},
+		{
+			name: "Trailing punctuation is not part of entities",
+			body: "Hi @Walt@BreakingBad.com. #go!",
+			want: []Entity{
+				{Kind: KindMention, Offset: 3, Length: 21, Text: "@Walt@BreakingBad.com", Value: "walt@breakingbad.com"},
+				{Kind: KindHashtag, Offset: 26, Length: 3, Text: "#go", Value: "go"},
+			},
+		},
+		{
+			name: "Offsets are counted in runes",
+			body: "Привет #мир",
+			want: []Entity{
+				{Kind: KindHashtag, Offset: 7, Length: 4, Text: "#мир", Value: "мир"},
+			},
+		},
+		{
+			name: "Links are shortened for display",
+			body: "Read https://www.example.com/articles/2024/a-very-long-title now",
+			want: []Entity{
+				{Kind: KindURL, Offset: 5, Length: 55, Text: "https://www.example.com/articles/2024/a-very-long-title", Value: "https://www.example.com/articles/2024/a-very-long-title", Display: "example.com/articles/202…"},
+			},
+		},
+		{
+			name: "Hashtags and mentions inside links are part of the link",
+			body: "(see https://example.com/#top?u=@walt@breakingbad.com).",
+			want: []Entity{
+				{Kind: KindURL, Offset: 5, Length: 48, Text: "https://example.com/#top?u=@walt@breakingbad.com", Value: "https://example.com/#top?u=@walt@breakingbad.com", Display: "example.com/#top?u=@walt…"},
+			},
+		},
+		{
+			name: "Balanced parentheses stay in links",
+			body: "https://en.wikipedia.org/wiki/Go_(game)!",
+			want: []Entity{
+				{Kind: KindURL, Offset: 0, Length: 39, Text: "https://en.wikipedia.org/wiki/Go_(game)", Value: "https://en.wikipedia.org/wiki/Go_(game)", Display: "en.wikipedia.org/wiki/Go…"},
+			},
+		},
+		{
+			name: "Numbers, anchors and emails are ignored",
+			body: "issue#12 costs #1 mail walt@breakingbad.com",
+			want: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Extract(tt.body)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Extract() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHashtagsAreDistinct(t *testing.T) {
+	got := Hashtags("#Go #go #golang")
+	want := []string{"go", "golang"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Hashtags() = %v, want %v", got, want)
+	}
+}
+
+func TestLength(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+		want int
+	}{
+		{
+			name: "Plain text",
+			body: "Hello, world",
+			want: 12,
+		},
+		{
+			name: "Long links count as URLLength",
+			body: "See https://example.com/" + strings.Repeat("a", 100),
+			want: 4 + URLLength,
+		},
+		{
+			name: "Short links count as URLLength too",
+			body: "http://a.io",
+			want: URLLength,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Length(tt.body); got != tt.want {
+				t.Errorf("Length() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestURLIndexes(t *testing.T) {
+	body := "See https://example.com/a, then (http://b.io)."
+	got := URLIndexes(body)
+	want := [][2]int{{4, 25}, {33, 44}}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("URLIndexes() = %v, want %v", got, want)
+	}
+	for _, idx := range got {
+		if s := body[idx[0]:idx[1]]; !strings.HasPrefix(s, "http") {
+			t.Errorf("URLIndexes() range %v = %q, want a link", idx, s)
+		}
+	}
+}
@@ -0,0 +1,128 @@
+// Package entitlements decides what a user's plan lets them do.
+package entitlements
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/DanilShapilov/chirpy/internal/entities"
+)
+
+type Plan string
+
+const (
+	PlanFree Plan = "free"
+	PlanRed  Plan = "chirpy_red"
+)
+
+// MaxChirpBytes caps the raw body of a chirp, which links would
+// otherwise let grow without bounds.
+const MaxChirpBytes = 8192
+
+var (
+	// ErrChirpTooLong is returned for chirps over the limit of every plan.
+	ErrChirpTooLong = errors.New("Chirp is too long")
+	ErrURLTooLong   = fmt.Errorf("Links can be at most %d characters long", entities.MaxURLLength)
+)
+
+type Entitlements struct {
+	Plan Plan
+	// MaxChirpLength is counted in characters, with every link counted
+	// as entities.URLLength.
+	MaxChirpLength int
+	// EditWindow is how long after creation a chirp can be edited. Zero
+	// means chirps can't be edited at all.
+	EditWindow time.Duration
+	// SearchBoost multiplies the search rank of the user's chirps.
+	SearchBoost float32
+}
+
+var (
+	Free = Entitlements{
+		Plan:           PlanFree,
+		MaxChirpLength: 140,
+		EditWindow:     15 * time.Minute,
+		SearchBoost:    1,
+	}
+	Red = Entitlements{
+		Plan:           PlanRed,
+		MaxChirpLength: 280,
+		EditWindow:     time.Hour,
+		SearchBoost:    1.5,
+	}
+)
+
+// For returns the entitlements of a user.
+func For(isChirpyRed bool) Entitlements {
+	if isChirpyRed {
+		return Red
+	}
+	return Free
+}
+
+// LimitError reports an action the user's plan doesn't allow.
+// UpgradeRequired is set when Chirpy Red would allow it.
+type LimitError struct {
+	Message         string
+	UpgradeRequired bool
+}
+
+func (e *LimitError) Error() string {
+	return e.Message
+}
+
+// StatusCode is 402 Payment Required when upgrading would help and 403
+// Forbidden otherwise.
+func (e *LimitError) StatusCode() int {
+	if e.UpgradeRequired {
+		return http.StatusPaymentRequired
+	}
+	return http.StatusForbidden
+}
+
+// CheckChirpLength returns a *LimitError when the body only fits a higher
+// plan, ErrChirpTooLong when it fits none and ErrURLTooLong when one of
+// its links is too long.
+func (e Entitlements) CheckChirpLength(body string) error {
+	if len(body) > MaxChirpBytes {
+		return ErrChirpTooLong
+	}
+	for _, link := range entities.URLIndexes(body) {
+		if link[1]-link[0] > entities.MaxURLLength {
+			return ErrURLTooLong
+		}
+	}
+
+	length := entities.Length(body)
+	if length <= e.MaxChirpLength {
+		return nil
+	}
+	if e.Plan != PlanRed && length <= Red.MaxChirpLength {
+		return &LimitError{
+			Message:         fmt.Sprintf("Chirps longer than %d characters require Chirpy Red", e.MaxChirpLength),
+			UpgradeRequired: true,
+		}
+	}
+	return ErrChirpTooLong
+}
+
+// CheckEdit returns a *LimitError when a chirp created at createdAt can't
+// be edited at now.
+func (e Entitlements) CheckEdit(createdAt, now time.Time) error {
+	age := now.Sub(createdAt)
+	if e.EditWindow > 0 && age <= e.EditWindow {
+		return nil
+	}
+	if e.Plan != PlanRed && age <= Red.EditWindow {
+		return &LimitError{
+			Message:         fmt.Sprintf("Editing chirps after %s requires Chirpy Red", e.EditWindow),
+			UpgradeRequired: true,
+		}
+	}
+	if e.EditWindow == 0 {
+		return &LimitError{Message: "Chirps can't be edited"}
+	}
+	return &LimitError{Message: "Edit window has expired"}
+}
@@ -0,0 +1,149 @@
+package entitlements
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCheckChirpLength(t *testing.T) {
+	tests := []struct {
+		name       string
+		ent        Entitlements
+		body       string
+		wantErr    error
+		wantStatus int
+	}{
+		{
+			name: "Free within limit",
+			ent:  Free,
+			body: strings.Repeat("a", 140),
+		},
+		{
+			name:       "Free over limit",
+			ent:        Free,
+			body:       strings.Repeat("a", 141),
+			wantStatus: http.StatusPaymentRequired,
+		},
+		{
+			name:    "Free over every limit",
+			ent:     Free,
+			body:    strings.Repeat("a", 281),
+			wantErr: ErrChirpTooLong,
+		},
+		{
+			name: "Red within limit",
+			ent:  Red,
+			body: strings.Repeat("a", 280),
+		},
+		{
+			name:    "Red over limit",
+			ent:     Red,
+			body:    strings.Repeat("a", 281),
+			wantErr: ErrChirpTooLong,
+		},
+		{
+			name: "Length is counted in characters",
+			ent:  Free,
+			body: strings.Repeat("é", 140),
+		},
+		{
+			name: "Links count as a fixed length",
+			ent:  Free,
+			body: strings.Repeat("a", 100) + " https://example.com/" + strings.Repeat("b", 200),
+		},
+		{
+			name:    "Links have a maximum length",
+			ent:     Red,
+			body:    "https://example.com/" + strings.Repeat("b", 2048),
+			wantErr: ErrURLTooLong,
+		},
+		{
+			name:    "Body has a maximum size",
+			ent:     Red,
+			body:    strings.Repeat("https://example.com/"+strings.Repeat("b", 2000)+" ", 5),
+			wantErr: ErrChirpTooLong,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.ent.CheckChirpLength(tt.body)
+			checkErr(t, err, tt.wantErr, tt.wantStatus)
+		})
+	}
+}
+
+func TestCheckEdit(t *testing.T) {
+	now := time.Now()
+	tests := []struct {
+		name       string
+		ent        Entitlements
+		createdAt  time.Time
+		wantStatus int
+	}{
+		{
+			name:      "Free within window",
+			ent:       Free,
+			createdAt: now.Add(-time.Minute),
+		},
+		{
+			name:       "Free after window, within Red's",
+			ent:        Free,
+			createdAt:  now.Add(-30 * time.Minute),
+			wantStatus: http.StatusPaymentRequired,
+		},
+		{
+			name:       "Free after Red's window",
+			ent:        Free,
+			createdAt:  now.Add(-2 * time.Hour),
+			wantStatus: http.StatusForbidden,
+		},
+		{
+			name:      "Red within window",
+			ent:       Red,
+			createdAt: now.Add(-time.Minute),
+		},
+		{
+			name:       "Red after window",
+			ent:        Red,
+			createdAt:  now.Add(-2 * time.Hour),
+			wantStatus: http.StatusForbidden,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.ent.CheckEdit(tt.createdAt, now)
+			checkErr(t, err, nil, tt.wantStatus)
+		})
+	}
+}
+
+func TestFor(t *testing.T) {
+	if got := For(true).Plan; got != PlanRed {
+		t.Errorf("For(true).Plan = %q, want %q", got, PlanRed)
+	}
+	if got := For(false).Plan; got != PlanFree {
+		t.Errorf("For(false).Plan = %q, want %q", got, PlanFree)
+	}
+}
+
+func checkErr(t *testing.T, err, wantErr error, wantStatus int) {
+	t.Helper()
+	if wantStatus != 0 {
+		var limitErr *LimitError
+		if !errors.As(err, &limitErr) {
+			t.Fatalf("error = %v, want *LimitError", err)
+		}
+		if got := limitErr.StatusCode(); got != wantStatus {
+			t.Errorf("StatusCode() = %d, want %d", got, wantStatus)
+		}
+		return
+	}
+	if !errors.Is(err, wantErr) {
+		t.Errorf("error = %v, want %v", err, wantErr)
+	}
+}
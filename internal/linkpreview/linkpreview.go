@@ -0,0 +1,268 @@
+// Package linkpreview fetches the OpenGraph metadata of links posted in
+// chirps. Its HTTP client refuses to connect to private and other
+// non-public addresses, so chirp authors can't use it to reach internal
+// services.
+package linkpreview
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"html"
+	"io"
+	"mime"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"regexp"
+	"strings"
+	"syscall"
+	"time"
+)
+
+var (
+	// ErrBlockedAddress is returned for links that resolve to an address
+	// the client isn't allowed to connect to.
+	ErrBlockedAddress = errors.New("address is not allowed")
+	ErrNotHTML        = errors.New("response is not HTML")
+)
+
+type Preview struct {
+	Title       string
+	Description string
+	ImageURL    string
+	SiteName    string
+}
+
+type Options struct {
+	// Allow lists addresses the client may connect to even though they
+	// aren't public, on any port. Tests allow 127.0.0.0/8 to reach
+	// httptest servers.
+	Allow []netip.Prefix
+	// Timeout bounds a whole fetch including redirects. Defaults to 5s.
+	Timeout time.Duration
+	// MaxBodySize is how much of a page is read. Defaults to 1 MiB.
+	MaxBodySize int64
+}
+
+const (
+	defaultTimeout     = 5 * time.Second
+	defaultMaxBodySize = 1 << 20
+	maxRedirects       = 3
+	userAgent          = "ChirpyBot/1.0 (+link previews)"
+)
+
+// blockedPrefixes are special-purpose ranges that netip.Addr has no
+// predicate for.
+var blockedPrefixes = []netip.Prefix{
+	netip.MustParsePrefix("0.0.0.0/8"),
+	netip.MustParsePrefix("100.64.0.0/10"),
+	netip.MustParsePrefix("192.0.0.0/24"),
+	netip.MustParsePrefix("198.18.0.0/15"),
+	netip.MustParsePrefix("240.0.0.0/4"),
+	netip.MustParsePrefix("64:ff9b::/96"),
+}
+
+type Client struct {
+	http        *http.Client
+	allow       []netip.Prefix
+	maxBodySize int64
+}
+
+func New(opts Options) *Client {
+	c := &Client{
+		allow:       opts.Allow,
+		maxBodySize: opts.MaxBodySize,
+	}
+	if c.maxBodySize == 0 {
+		c.maxBodySize = defaultMaxBodySize
+	}
+	timeout := opts.Timeout
+	if timeout == 0 {
+		timeout = defaultTimeout
+	}
+
+	dialer := &net.Dialer{
+		Timeout: timeout,
+		// The address is checked after DNS resolution, right before
+		// connecting, so a name can't be rebound to an internal address
+		// between the check and the connection.
+		Control: func(network, address string, _ syscall.RawConn) error {
+			return c.checkAddress(address)
+		},
+	}
+	c.http = &http.Client{
+		Timeout: timeout,
+		Transport: &http.Transport{
+			// A proxy would make the dialer check the proxy instead of
+			// the link.
+			Proxy:               nil,
+			DialContext:         dialer.DialContext,
+			TLSHandshakeTimeout: timeout,
+			MaxIdleConns:        10,
+			IdleConnTimeout:     30 * time.Second,
+		},
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			if len(via) >= maxRedirects {
+				return errors.New("too many redirects")
+			}
+			return checkScheme(req.URL)
+		},
+	}
+	return c
+}
+
+// ParseAllowlist parses a comma separated list of IP addresses and
+// CIDR prefixes.
+func ParseAllowlist(s string) ([]netip.Prefix, error) {
+	var res []netip.Prefix
+	for _, item := range strings.Split(s, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		if prefix, err := netip.ParsePrefix(item); err == nil {
+			res = append(res, prefix.Masked())
+			continue
+		}
+		addr, err := netip.ParseAddr(item)
+		if err != nil {
+			return nil, fmt.Errorf("invalid address %q", item)
+		}
+		res = append(res, netip.PrefixFrom(addr, addr.BitLen()))
+	}
+	return res, nil
+}
+
+func (c *Client) checkAddress(address string) error {
+	addrPort, err := netip.ParseAddrPort(address)
+	if err != nil {
+		return fmt.Errorf("%w: %s", ErrBlockedAddress, address)
+	}
+	addr := addrPort.Addr().Unmap()
+	for _, prefix := range c.allow {
+		if prefix.Contains(addr) {
+			return nil
+		}
+	}
+
+	if port := addrPort.Port(); port != 80 && port != 443 {
+		return fmt.Errorf("%w: %s", ErrBlockedAddress, address)
+	}
+	if !addr.IsGlobalUnicast() || addr.IsPrivate() || addr.IsLoopback() || addr.IsLinkLocalUnicast() {
+		return fmt.Errorf("%w: %s", ErrBlockedAddress, address)
+	}
+	for _, prefix := range blockedPrefixes {
+		if prefix.Contains(addr) {
+			return fmt.Errorf("%w: %s", ErrBlockedAddress, address)
+		}
+	}
+	return nil
+}
+
+func checkScheme(u *url.URL) error {
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return fmt.Errorf("unsupported scheme %q", u.Scheme)
+	}
+	return nil
+}
+
+// Fetch downloads the page at rawURL and reads its OpenGraph metadata,
+// falling back to the <title> and the description meta tag.
+func (c *Client) Fetch(ctx context.Context, rawURL string) (Preview, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return Preview{}, err
+	}
+	if err := checkScheme(u); err != nil {
+		return Preview{}, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return Preview{}, err
+	}
+	req.Header.Set("User-Agent", userAgent)
+	req.Header.Set("Accept", "text/html")
+
+	res, err := c.http.Do(req)
+	if err != nil {
+		return Preview{}, err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return Preview{}, fmt.Errorf("unexpected status %d", res.StatusCode)
+	}
+	mediaType, _, err := mime.ParseMediaType(res.Header.Get("Content-Type"))
+	if err != nil || (mediaType != "text/html" && mediaType != "application/xhtml+xml") {
+		return Preview{}, ErrNotHTML
+	}
+
+	page, err := io.ReadAll(io.LimitReader(res.Body, c.maxBodySize))
+	if err != nil {
+		return Preview{}, err
+	}
+	preview := Parse(string(page))
+	if preview.ImageURL != "" {
+		preview.ImageURL = resolveURL(res.Request.URL, preview.ImageURL)
+	}
+	return preview, nil
+}
+
+var (
+	metaRegex  = regexp.MustCompile(`(?is)<meta\s[^>]*>`)
+	attrRegex  = regexp.MustCompile(`(?is)([a-z][a-z0-9:_-]*)\s*=\s*(?:"([^"]*)"|'([^']*)'|([^\s"'>]+))`)
+	titleRegex = regexp.MustCompile(`(?is)<title[^>]*>(.*?)</title>`)
+)
+
+// Parse reads the preview out of an HTML page. It only looks at <meta>
+// and <title> tags, which is all OpenGraph needs.
+func Parse(page string) Preview {
+	meta := make(map[string]string)
+	for _, tag := range metaRegex.FindAllString(page, -1) {
+		attrs := make(map[string]string)
+		for _, m := range attrRegex.FindAllStringSubmatch(tag, -1) {
+			attrs[strings.ToLower(m[1])] = m[2] + m[3] + m[4]
+		}
+		key := attrs["property"]
+		if key == "" {
+			key = attrs["name"]
+		}
+		key = strings.ToLower(key)
+		if _, ok := meta[key]; key != "" && !ok {
+			meta[key] = clean(attrs["content"])
+		}
+	}
+
+	preview := Preview{
+		Title:       meta["og:title"],
+		Description: meta["og:description"],
+		ImageURL:    meta["og:image"],
+		SiteName:    meta["og:site_name"],
+	}
+	if preview.Title == "" {
+		if m := titleRegex.FindStringSubmatch(page); m != nil {
+			preview.Title = clean(m[1])
+		}
+	}
+	if preview.Description == "" {
+		preview.Description = meta["description"]
+	}
+	return preview
+}
+
+func clean(s string) string {
+	return strings.Join(strings.Fields(html.UnescapeString(s)), " ")
+}
+
+// resolveURL makes a relative og:image absolute. Images that aren't
+// http(s) are dropped.
+func resolveURL(base *url.URL, ref string) string {
+	u, err := base.Parse(ref)
+	if err != nil || checkScheme(u) != nil {
+		return ""
+	}
+	return u.String()
+}
@@ -0,0 +1,124 @@
+package linkpreview
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"testing"
+)
+
+const page = `<!DOCTYPE html>
+<html><head>
+<title>Fallback title</title>
+<meta property="og:title" content="Breaking &amp; Bad">
+<meta content='The  one who knocks' property='og:description'>
+<meta property="og:image" content="/images/walt.png">
+<meta property="og:site_name" content="AMC">
+</head><body>Hello</body></html>`
+
+func allowLoopback(t *testing.T) []netip.Prefix {
+	t.Helper()
+	allow, err := ParseAllowlist("127.0.0.0/8, ::1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return allow
+}
+
+func TestFetch(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		switch req.URL.Path {
+		case "/redirect":
+			http.Redirect(w, req, "/page", http.StatusFound)
+		case "/page":
+			w.Header().Set("Content-Type", "text/html; charset=utf-8")
+			w.Write([]byte(page))
+		default:
+			w.Header().Set("Content-Type", "application/json")
+			w.Write([]byte("{}"))
+		}
+	}))
+	defer server.Close()
+
+	client := New(Options{Allow: allowLoopback(t)})
+
+	got, err := client.Fetch(context.Background(), server.URL+"/redirect")
+	if err != nil {
+		t.Fatalf("Fetch() error = %v", err)
+	}
+	want := Preview{
+		Title:       "Breaking & Bad",
+		Description: "The one who knocks",
+		ImageURL:    server.URL + "/images/walt.png",
+		SiteName:    "AMC",
+	}
+	if got != want {
+		t.Errorf("Fetch() = %+v, want %+v", got, want)
+	}
+
+	if _, err := client.Fetch(context.Background(), server.URL+"/json"); !errors.Is(err, ErrNotHTML) {
+		t.Errorf("Fetch() error = %v, want %v", err, ErrNotHTML)
+	}
+}
+
+func TestFetchBlocksPrivateAddresses(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		t.Error("blocked server was reached")
+	}))
+	defer server.Close()
+
+	client := New(Options{})
+	_, err := client.Fetch(context.Background(), server.URL)
+	if !errors.Is(err, ErrBlockedAddress) {
+		t.Errorf("Fetch() error = %v, want %v", err, ErrBlockedAddress)
+	}
+}
+
+func TestFetchRejectsOtherSchemes(t *testing.T) {
+	client := New(Options{Allow: allowLoopback(t)})
+	if _, err := client.Fetch(context.Background(), "file:///etc/passwd"); err == nil {
+		t.Error("Fetch() error = nil, want an error")
+	}
+}
+
+func TestCheckAddress(t *testing.T) {
+	client := New(Options{})
+	tests := []struct {
+		address string
+		allowed bool
+	}{
+		{"93.184.215.14:443", true},
+		{"[2606:2800:21f:cb07:6820:80da:af6b:8b2c]:80", true},
+		{"93.184.215.14:22", false},
+		{"127.0.0.1:80", false},
+		{"10.0.0.1:80", false},
+		{"169.254.169.254:80", false},
+		{"100.64.0.1:80", false},
+		{"0.0.0.0:80", false},
+		{"[::1]:80", false},
+		{"[::ffff:127.0.0.1]:80", false},
+		{"[fd00::1]:443", false},
+		{"[fe80::1]:443", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.address, func(t *testing.T) {
+			err := client.checkAddress(tt.address)
+			if (err == nil) != tt.allowed {
+				t.Errorf("checkAddress() error = %v, want allowed = %v", err, tt.allowed)
+			}
+		})
+	}
+}
+
+func TestParseAllowlist(t *testing.T) {
+	if _, err := ParseAllowlist("10.0.0.0/8, not-an-ip"); err == nil {
+		t.Error("ParseAllowlist() error = nil, want an error")
+	}
+	got, err := ParseAllowlist("")
+	if err != nil || len(got) != 0 {
+		t.Errorf("ParseAllowlist(\"\") = %v, %v, want nothing", got, err)
+	}
+}
@@ -0,0 +1,65 @@
+package lru
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCacheEvictsLeastRecentlyUsed(t *testing.T) {
+	c := New[string, int](2, 0)
+	c.Add("a", 1)
+	c.Add("b", 2)
+	if _, ok := c.Get("a"); !ok {
+		t.Fatal("Get(a) missing")
+	}
+	c.Add("c", 3)
+
+	if _, ok := c.Get("b"); ok {
+		t.Error("Get(b) found, want it evicted")
+	}
+	if v, ok := c.Get("a"); !ok || v != 1 {
+		t.Errorf("Get(a) = %v, %v, want 1, true", v, ok)
+	}
+	if v, ok := c.Get("c"); !ok || v != 3 {
+		t.Errorf("Get(c) = %v, %v, want 3, true", v, ok)
+	}
+	if c.Len() != 2 {
+		t.Errorf("Len() = %d, want 2", c.Len())
+	}
+}
+
+func TestCacheExpires(t *testing.T) {
+	now := time.Now()
+	c := New[string, int](10, time.Minute)
+	c.now = func() time.Time { return now }
+
+	c.Add("a", 1)
+	now = now.Add(59 * time.Second)
+	if _, ok := c.Get("a"); !ok {
+		t.Error("Get(a) missing before the TTL")
+	}
+
+	c.Add("a", 2)
+	now = now.Add(59 * time.Second)
+	if v, ok := c.Get("a"); !ok || v != 2 {
+		t.Errorf("Get(a) = %v, %v, want 2, true: Add should reset the TTL", v, ok)
+	}
+
+	now = now.Add(time.Minute)
+	if _, ok := c.Get("a"); ok {
+		t.Error("Get(a) found after the TTL")
+	}
+	if c.Len() != 0 {
+		t.Errorf("Len() = %d, want expired entry dropped", c.Len())
+	}
+}
+
+func TestCacheRemove(t *testing.T) {
+	c := New[int, string](10, 0)
+	c.Add(1, "one")
+	c.Remove(1)
+	c.Remove(2)
+	if _, ok := c.Get(1); ok {
+		t.Error("Get(1) found after Remove")
+	}
+}
@@ -0,0 +1,71 @@
+package media
+
+import (
+	"encoding/binary"
+	"errors"
+)
+
+var errMalformedGIF = errors.New("malformed GIF")
+
+// gifFrames walks the blocks of a GIF without decoding any pixels and
+// returns its frame count and the pixels of all frames together, which
+// is what decoding it allocates.
+func gifFrames(data []byte) (frames int, pixels int64, err error) {
+	const headerSize = 6 + 7
+	if len(data) < headerSize {
+		return 0, 0, errMalformedGIF
+	}
+	i := headerSize
+	if flags := data[10]; flags&0x80 != 0 {
+		i += 3 << (flags&0x07 + 1)
+	}
+
+	for {
+		if i >= len(data) {
+			return 0, 0, errMalformedGIF
+		}
+		switch data[i] {
+		case 0x3B: // Trailer.
+			return frames, pixels, nil
+		case 0x21: // Extension: label, then data sub-blocks.
+			i, err = skipSubBlocks(data, i+2)
+			if err != nil {
+				return 0, 0, err
+			}
+		case 0x2C: // Image descriptor.
+			if i+10 > len(data) {
+				return 0, 0, errMalformedGIF
+			}
+			w := int64(binary.LittleEndian.Uint16(data[i+5:]))
+			h := int64(binary.LittleEndian.Uint16(data[i+7:]))
+			flags := data[i+9]
+			frames++
+			pixels += w * h
+			i += 10
+			if flags&0x80 != 0 {
+				i += 3 << (flags&0x07 + 1)
+			}
+			// LZW minimum code size, then the image data sub-blocks.
+			i, err = skipSubBlocks(data, i+1)
+			if err != nil {
+				return 0, 0, err
+			}
+		default:
+			return 0, 0, errMalformedGIF
+		}
+	}
+}
+
+func skipSubBlocks(data []byte, i int) (int, error) {
+	for {
+		if i >= len(data) {
+			return 0, errMalformedGIF
+		}
+		size := int(data[i])
+		i++
+		if size == 0 {
+			return i, nil
+		}
+		i += size
+	}
+}
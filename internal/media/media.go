@@ -0,0 +1,191 @@
+// Package media validates uploaded images, strips their metadata and
+// generates thumbnails.
+package media
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+	"net/http"
+)
+
+const (
+	// MaxDimension guards against decompression bombs: images are
+	// rejected before decoding when either side is larger.
+	MaxDimension = 8192
+	// MaxGIFFrames and MaxGIFPixels bound what decoding an animated GIF
+	// allocates. The pixels of all frames count towards MaxGIFPixels.
+	MaxGIFFrames = 300
+	MaxGIFPixels = 100_000_000
+	// ThumbnailSize is the longest side of a generated thumbnail.
+	ThumbnailSize = 320
+)
+
+var ErrUnsupportedType = errors.New("unsupported image type")
+
+var extensions = map[string]string{
+	"image/jpeg": ".jpg",
+	"image/png":  ".png",
+	"image/gif":  ".gif",
+}
+
+// Image is an uploaded image re-encoded without its metadata, together
+// with its thumbnail.
+type Image struct {
+	ContentType string
+	Data        []byte
+	Width       int
+	Height      int
+
+	ThumbnailContentType string
+	Thumbnail            []byte
+	ThumbnailWidth       int
+	ThumbnailHeight      int
+}
+
+// Process sniffs the content type of data, ignoring whatever the client
+// claimed, and re-encodes the image. Re-encoding drops EXIF and any other
+// metadata, which can include the location a photo was taken at, so the
+// EXIF orientation of JPEGs is applied to the pixels beforehand.
+func Process(data []byte) (Image, error) {
+	contentType := http.DetectContentType(data)
+	if _, ok := extensions[contentType]; !ok {
+		return Image{}, fmt.Errorf("%w: %s", ErrUnsupportedType, contentType)
+	}
+
+	config, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return Image{}, fmt.Errorf("couldn't decode image: %w", err)
+	}
+	if config.Width > MaxDimension || config.Height > MaxDimension {
+		return Image{}, fmt.Errorf("image is larger than %dx%d", MaxDimension, MaxDimension)
+	}
+
+	res := Image{
+		ContentType: contentType,
+		Width:       config.Width,
+		Height:      config.Height,
+	}
+
+	var first image.Image
+	var buf bytes.Buffer
+	switch contentType {
+	case "image/gif":
+		frames, pixels, err := gifFrames(data)
+		if err != nil {
+			return Image{}, fmt.Errorf("couldn't decode image: %w", err)
+		}
+		if frames > MaxGIFFrames {
+			return Image{}, fmt.Errorf("GIF has more than %d frames", MaxGIFFrames)
+		}
+		if pixels > MaxGIFPixels {
+			return Image{}, fmt.Errorf("GIF has more than %d pixels in all frames", MaxGIFPixels)
+		}
+		// Keep every frame so animations survive.
+		g, err := gif.DecodeAll(bytes.NewReader(data))
+		if err != nil {
+			return Image{}, fmt.Errorf("couldn't decode image: %w", err)
+		}
+		if err := gif.EncodeAll(&buf, g); err != nil {
+			return Image{}, err
+		}
+		first = g.Image[0]
+	default:
+		img, _, err := image.Decode(bytes.NewReader(data))
+		if err != nil {
+			return Image{}, fmt.Errorf("couldn't decode image: %w", err)
+		}
+		if contentType == "image/jpeg" {
+			img = orient(img, jpegOrientation(data))
+			res.Width, res.Height = img.Bounds().Dx(), img.Bounds().Dy()
+		}
+		if err := encode(&buf, img, contentType); err != nil {
+			return Image{}, err
+		}
+		first = img
+	}
+	res.Data = buf.Bytes()
+
+	thumb := Thumbnail(first, ThumbnailSize)
+	res.ThumbnailContentType = contentType
+	if contentType == "image/gif" {
+		res.ThumbnailContentType = "image/png"
+	}
+	var thumbBuf bytes.Buffer
+	if err := encode(&thumbBuf, thumb, res.ThumbnailContentType); err != nil {
+		return Image{}, err
+	}
+	res.Thumbnail = thumbBuf.Bytes()
+	res.ThumbnailWidth = thumb.Bounds().Dx()
+	res.ThumbnailHeight = thumb.Bounds().Dy()
+	return res, nil
+}
+
+// Extension returns the file extension for a content type accepted by
+// Process, including the leading dot.
+func Extension(contentType string) string {
+	return extensions[contentType]
+}
+
+func encode(buf *bytes.Buffer, img image.Image, contentType string) error {
+	switch contentType {
+	case "image/jpeg":
+		return jpeg.Encode(buf, img, &jpeg.Options{Quality: 90})
+	case "image/png":
+		return png.Encode(buf, img)
+	default:
+		return fmt.Errorf("%w: %s", ErrUnsupportedType, contentType)
+	}
+}
+
+// Thumbnail scales img down so that its longest side is at most size,
+// averaging the source pixels that fall into each target pixel. Images
+// that are already small enough are returned as is.
+func Thumbnail(img image.Image, size int) image.Image {
+	bounds := img.Bounds()
+	srcW, srcH := bounds.Dx(), bounds.Dy()
+	if srcW <= size && srcH <= size {
+		return img
+	}
+
+	dstW, dstH := size, size
+	if srcW > srcH {
+		dstH = max(1, srcH*size/srcW)
+	} else {
+		dstW = max(1, srcW*size/srcH)
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, dstW, dstH))
+	for y := 0; y < dstH; y++ {
+		y0 := bounds.Min.Y + y*srcH/dstH
+		y1 := max(y0+1, bounds.Min.Y+(y+1)*srcH/dstH)
+		for x := 0; x < dstW; x++ {
+			x0 := bounds.Min.X + x*srcW/dstW
+			x1 := max(x0+1, bounds.Min.X+(x+1)*srcW/dstW)
+
+			var r, g, b, a, n uint64
+			for sy := y0; sy < y1; sy++ {
+				for sx := x0; sx < x1; sx++ {
+					cr, cg, cb, ca := img.At(sx, sy).RGBA()
+					r += uint64(cr)
+					g += uint64(cg)
+					b += uint64(cb)
+					a += uint64(ca)
+					n++
+				}
+			}
+			dst.Set(x, y, color.RGBA64{
+				R: uint16(r / n),
+				G: uint16(g / n),
+				B: uint16(b / n),
+				A: uint16(a / n),
+			})
+		}
+	}
+	return dst
+}
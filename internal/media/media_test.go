@@ -0,0 +1,190 @@
+package media
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"image/color"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+	"testing"
+)
+
+func makeImage(w, h int) image.Image {
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			img.Set(x, y, color.RGBA{R: uint8(x), G: uint8(y), B: 128, A: 255})
+		}
+	}
+	return img
+}
+
+// withEXIF inserts an APP1 segment right after the JPEG SOI marker.
+func withEXIF(data []byte) []byte {
+	payload := append([]byte("Exif\x00\x00"), []byte("GPS 51.5007,-0.1246")...)
+	length := len(payload) + 2
+	segment := append([]byte{0xFF, 0xE1, byte(length >> 8), byte(length)}, payload...)
+	return append(append(append([]byte{}, data[:2]...), segment...), data[2:]...)
+}
+
+// withOrientation inserts an EXIF segment holding only an Orientation
+// tag, in big endian TIFF byte order.
+func withOrientation(data []byte, orientation byte) []byte {
+	tiff := []byte{
+		'M', 'M', 0, 42, 0, 0, 0, 8, // Header, IFD0 at offset 8.
+		0, 1, // One entry.
+		0x01, 0x12, 0, 3, 0, 0, 0, 1, 0, orientation, 0, 0, // Orientation, SHORT.
+		0, 0, 0, 0, // No next IFD.
+	}
+	payload := append([]byte("Exif\x00\x00"), tiff...)
+	length := len(payload) + 2
+	segment := append([]byte{0xFF, 0xE1, byte(length >> 8), byte(length)}, payload...)
+	return append(append(append([]byte{}, data[:2]...), segment...), data[2:]...)
+}
+
+func TestProcess(t *testing.T) {
+	var jpegBuf bytes.Buffer
+	if err := jpeg.Encode(&jpegBuf, makeImage(640, 480), nil); err != nil {
+		t.Fatal(err)
+	}
+	var pngBuf bytes.Buffer
+	if err := png.Encode(&pngBuf, makeImage(100, 50)); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name            string
+		data            []byte
+		wantType        string
+		wantWidth       int
+		wantHeight      int
+		wantThumbWidth  int
+		wantThumbHeight int
+		wantErr         error
+	}{
+		{
+			name:            "JPEG with EXIF",
+			data:            withEXIF(jpegBuf.Bytes()),
+			wantType:        "image/jpeg",
+			wantWidth:       640,
+			wantHeight:      480,
+			wantThumbWidth:  ThumbnailSize,
+			wantThumbHeight: 240,
+		},
+		{
+			name:            "JPEG rotated by EXIF",
+			data:            withOrientation(jpegBuf.Bytes(), 6),
+			wantType:        "image/jpeg",
+			wantWidth:       480,
+			wantHeight:      640,
+			wantThumbWidth:  240,
+			wantThumbHeight: ThumbnailSize,
+		},
+		{
+			name:            "Small PNG",
+			data:            pngBuf.Bytes(),
+			wantType:        "image/png",
+			wantWidth:       100,
+			wantHeight:      50,
+			wantThumbWidth:  100,
+			wantThumbHeight: 50,
+		},
+		{
+			name:    "Not an image",
+			data:    []byte("<html><body>hi</body></html>"),
+			wantErr: ErrUnsupportedType,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			img, err := Process(tt.data)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Errorf("Process() error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Process() error = %v", err)
+			}
+			if img.ContentType != tt.wantType {
+				t.Errorf("ContentType = %q, want %q", img.ContentType, tt.wantType)
+			}
+			if img.Width != tt.wantWidth || img.Height != tt.wantHeight {
+				t.Errorf("size = %dx%d, want %dx%d", img.Width, img.Height, tt.wantWidth, tt.wantHeight)
+			}
+			if img.ThumbnailWidth != tt.wantThumbWidth || img.ThumbnailHeight != tt.wantThumbHeight {
+				t.Errorf("thumbnail size = %dx%d, want %dx%d", img.ThumbnailWidth, img.ThumbnailHeight, tt.wantThumbWidth, tt.wantThumbHeight)
+			}
+			if bytes.Contains(img.Data, []byte("Exif")) {
+				t.Errorf("Process() kept EXIF data")
+			}
+		})
+	}
+}
+
+func TestOrient(t *testing.T) {
+	// A 2x1 image, red on the left and blue on the right.
+	src := image.NewRGBA(image.Rect(0, 0, 2, 1))
+	red := color.RGBA{R: 255, A: 255}
+	blue := color.RGBA{B: 255, A: 255}
+	src.Set(0, 0, red)
+	src.Set(1, 0, blue)
+
+	tests := []struct {
+		orientation int
+		wantW       int
+		wantH       int
+		wantFirst   color.RGBA // Pixel at (0, 0).
+	}{
+		{1, 2, 1, red},
+		{2, 2, 1, blue},
+		{3, 2, 1, blue},
+		{6, 1, 2, red},
+		{8, 1, 2, blue},
+	}
+
+	for _, tt := range tests {
+		got := orient(src, tt.orientation)
+		if got.Bounds().Dx() != tt.wantW || got.Bounds().Dy() != tt.wantH {
+			t.Errorf("orient(%d) size = %v, want %dx%d", tt.orientation, got.Bounds().Size(), tt.wantW, tt.wantH)
+			continue
+		}
+		if c := color.RGBAModel.Convert(got.At(0, 0)); c != tt.wantFirst {
+			t.Errorf("orient(%d) at (0, 0) = %v, want %v", tt.orientation, c, tt.wantFirst)
+		}
+	}
+}
+
+func TestProcessGIFLimits(t *testing.T) {
+	palette := color.Palette{color.Black, color.White}
+	g := &gif.GIF{}
+	for i := 0; i < MaxGIFFrames+1; i++ {
+		g.Image = append(g.Image, image.NewPaletted(image.Rect(0, 0, 2, 2), palette))
+		g.Delay = append(g.Delay, 1)
+	}
+	var buf bytes.Buffer
+	if err := gif.EncodeAll(&buf, g); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Process(buf.Bytes()); err == nil {
+		t.Error("Process() error = nil, want too many frames")
+	}
+
+	frames, pixels, err := gifFrames(buf.Bytes())
+	if err != nil || frames != MaxGIFFrames+1 || pixels != int64(4*(MaxGIFFrames+1)) {
+		t.Errorf("gifFrames() = %d, %d, %v", frames, pixels, err)
+	}
+
+	g.Image, g.Delay = g.Image[:1], g.Delay[:1]
+	buf.Reset()
+	if err := gif.EncodeAll(&buf, g); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Process(buf.Bytes()); err != nil {
+		t.Errorf("Process() error = %v", err)
+	}
+}
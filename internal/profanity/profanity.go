@@ -0,0 +1,161 @@
+// Package profanity masks banned words in user submitted text.
+package profanity
+
+import (
+	"bufio"
+	"os"
+	"strings"
+	"sync"
+	"unicode"
+)
+
+const DefaultMask = "****"
+
+// DefaultWords is the list Chirpy has always shipped with.
+var DefaultWords = []string{"kerfuffle", "sharbert", "fornax"}
+
+var leetReplacer = strings.NewReplacer(
+	"0", "o",
+	"1", "i",
+	"3", "e",
+	"4", "a",
+	"5", "s",
+	"7", "t",
+	"@", "a",
+	"$", "s",
+)
+
+type Options struct {
+	// Mask replaces every banned word. DefaultMask is used when empty.
+	Mask string
+	// Leet makes "k3rfuffl3" match "kerfuffle".
+	Leet bool
+}
+
+// Filter is safe for concurrent use. Its word list can be swapped at
+// runtime with SetWords.
+type Filter struct {
+	mask string
+	leet bool
+
+	mu    sync.RWMutex
+	words map[string]struct{}
+}
+
+func New(words []string, opts Options) *Filter {
+	f := &Filter{
+		mask: opts.Mask,
+		leet: opts.Leet,
+	}
+	if f.mask == "" {
+		f.mask = DefaultMask
+	}
+	f.SetWords(words)
+	return f
+}
+
+func (f *Filter) SetWords(words []string) {
+	set := make(map[string]struct{}, len(words))
+	for _, w := range words {
+		if w = f.normalize(w); w != "" {
+			set[w] = struct{}{}
+		}
+	}
+	f.mu.Lock()
+	f.words = set
+	f.mu.Unlock()
+}
+
+// Clean replaces banned words in text with the mask and returns the
+// masked words as they appeared in text. Words are split on anything
+// that isn't a letter or a digit, so punctuation and case don't matter.
+func (f *Filter) Clean(text string) (string, []string) {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+
+	var b strings.Builder
+	var masked []string
+	start := -1
+	flush := func(end int) {
+		if start < 0 {
+			return
+		}
+		word := text[start:end]
+		if _, ok := f.words[f.normalize(word)]; ok {
+			b.WriteString(f.mask)
+			masked = append(masked, word)
+		} else {
+			b.WriteString(word)
+		}
+		start = -1
+	}
+
+	for i, r := range text {
+		if f.isWordRune(r) {
+			if start < 0 {
+				start = i
+			}
+			continue
+		}
+		flush(i)
+		b.WriteRune(r)
+	}
+	flush(len(text))
+	return b.String(), masked
+}
+
+// CleanExcept is Clean, except that the byte ranges in keep, ordered
+// and not overlapping, are left as they are. Chirps keep their links
+// intact this way.
+func (f *Filter) CleanExcept(text string, keep [][2]int) (string, []string) {
+	var b strings.Builder
+	var masked []string
+	prev := 0
+	for _, k := range append(keep, [2]int{len(text), len(text)}) {
+		cleaned, m := f.Clean(text[prev:k[0]])
+		b.WriteString(cleaned)
+		b.WriteString(text[k[0]:k[1]])
+		masked = append(masked, m...)
+		prev = k[1]
+	}
+	return b.String(), masked
+}
+
+func (f *Filter) isWordRune(r rune) bool {
+	if unicode.IsLetter(r) || unicode.IsDigit(r) {
+		return true
+	}
+	return f.leet && (r == '@' || r == '$')
+}
+
+func (f *Filter) normalize(word string) string {
+	word = strings.ToLower(strings.TrimSpace(word))
+	if f.leet {
+		word = leetReplacer.Replace(word)
+	}
+	return word
+}
+
+// LoadWordsFile reads a word list with one word per line. Blank lines
+// and lines starting with '#' are skipped.
+func LoadWordsFile(path string) ([]string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var words []string
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		words = append(words, line)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return words, nil
+}
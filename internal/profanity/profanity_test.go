@@ -0,0 +1,116 @@
+package profanity
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestClean(t *testing.T) {
+	tests := []struct {
+		name       string
+		opts       Options
+		text       string
+		wantText   string
+		wantMasked []string
+	}{
+		{
+			name:       "Clean text",
+			text:       "I had something interesting for breakfast",
+			wantText:   "I had something interesting for breakfast",
+			wantMasked: nil,
+		},
+		{
+			name:       "Case and punctuation are ignored",
+			text:       "Kerfuffle! What a fornax.",
+			wantText:   "****! What a ****.",
+			wantMasked: []string{"Kerfuffle", "fornax"},
+		},
+		{
+			name:       "Whitespace is preserved",
+			text:       "Sharbert\tand  sharbert",
+			wantText:   "****\tand  ****",
+			wantMasked: []string{"Sharbert", "sharbert"},
+		},
+		{
+			name:       "Leet speak is ignored by default",
+			text:       "k3rfuffl3",
+			wantText:   "k3rfuffl3",
+			wantMasked: nil,
+		},
+		{
+			name:       "Leet speak",
+			opts:       Options{Leet: true},
+			text:       "k3rfuffl3 and $harbert",
+			wantText:   "**** and ****",
+			wantMasked: []string{"k3rfuffl3", "$harbert"},
+		},
+		{
+			name:       "Custom mask",
+			opts:       Options{Mask: "[censored]"},
+			text:       "fornax",
+			wantText:   "[censored]",
+			wantMasked: []string{"fornax"},
+		},
+		{
+			name:       "Unicode words",
+			text:       "Fornax—это слово",
+			wantText:   "****—это слово",
+			wantMasked: []string{"Fornax"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := New(DefaultWords, tt.opts)
+			gotText, gotMasked := f.Clean(tt.text)
+			if gotText != tt.wantText {
+				t.Errorf("Clean() text = %q, want %q", gotText, tt.wantText)
+			}
+			if !reflect.DeepEqual(gotMasked, tt.wantMasked) {
+				t.Errorf("Clean() masked = %v, want %v", gotMasked, tt.wantMasked)
+			}
+		})
+	}
+}
+
+func TestCleanExcept(t *testing.T) {
+	f := New(DefaultWords, Options{})
+	text := "kerfuffle https://example.com/kerfuffle kerfuffle"
+	got, masked := f.CleanExcept(text, [][2]int{{10, 39}})
+	want := "**** https://example.com/kerfuffle ****"
+	if got != want {
+		t.Errorf("CleanExcept() = %q, want %q", got, want)
+	}
+	if len(masked) != 2 {
+		t.Errorf("CleanExcept() masked = %v, want 2 words", masked)
+	}
+}
+
+func TestSetWords(t *testing.T) {
+	f := New(DefaultWords, Options{})
+	f.SetWords([]string{"Gizmo"})
+
+	got, _ := f.Clean("gizmo fornax")
+	if want := "**** fornax"; got != want {
+		t.Errorf("Clean() = %q, want %q", got, want)
+	}
+}
+
+func TestLoadWordsFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "words.txt")
+	content := "# banned words\nkerfuffle\n\n  gizmo  \n"
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := LoadWordsFile(path)
+	if err != nil {
+		t.Fatalf("LoadWordsFile() error = %v", err)
+	}
+	want := []string{"kerfuffle", "gizmo"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("LoadWordsFile() = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,156 @@
+// Package spam screens new chirps with a pipeline of pluggable rule
+// checkers.
+package spam
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/DanilShapilov/chirpy/internal/entities"
+)
+
+// Post is the part of a chirp the checkers look at.
+type Post struct {
+	Body      string
+	CreatedAt time.Time
+}
+
+// Input is what a checker sees: the new chirp and the author's earlier
+// chirps posted within the pipeline's lookback, newest first.
+type Input struct {
+	Chirp  Post
+	Recent []Post
+}
+
+// Decision explains why a chirp was flagged.
+type Decision struct {
+	Rule   string
+	Reason string
+}
+
+// Checker is a single rule. Check returns the reason when the chirp
+// breaks the rule.
+type Checker interface {
+	Rule() string
+	Check(in Input) (reason string, flagged bool)
+}
+
+// historyChecker is implemented by checkers that look at earlier
+// chirps. Lookback is how far back they look.
+type historyChecker interface {
+	Lookback() time.Duration
+}
+
+type Pipeline struct {
+	checkers []Checker
+	lookback time.Duration
+}
+
+func NewPipeline(checkers ...Checker) *Pipeline {
+	p := &Pipeline{checkers: checkers}
+	for _, checker := range checkers {
+		if h, ok := checker.(historyChecker); ok {
+			p.lookback = max(p.lookback, h.Lookback())
+		}
+	}
+	return p
+}
+
+// Lookback is how far back the author's earlier chirps have to be
+// loaded for the checkers. Zero means none of them are needed.
+func (p *Pipeline) Lookback() time.Duration {
+	return p.lookback
+}
+
+// Check runs the checkers in order and returns the decision of the
+// first one that flags the chirp.
+func (p *Pipeline) Check(in Input) (Decision, bool) {
+	for _, checker := range p.checkers {
+		if reason, flagged := checker.Check(in); flagged {
+			return Decision{Rule: checker.Rule(), Reason: reason}, true
+		}
+	}
+	return Decision{}, false
+}
+
+// Duplicates flags a chirp when the author already posted the same body
+// Max times within Window. Case and whitespace are ignored.
+type Duplicates struct {
+	Max    int
+	Window time.Duration
+}
+
+func (d Duplicates) Rule() string { return "duplicates" }
+
+func (d Duplicates) Lookback() time.Duration { return d.Window }
+
+func (d Duplicates) Check(in Input) (string, bool) {
+	body := normalize(in.Chirp.Body)
+	since := in.Chirp.CreatedAt.Add(-d.Window)
+	count := 0
+	for _, post := range in.Recent {
+		if !posted(post, since, in.Chirp.CreatedAt) {
+			continue
+		}
+		if normalize(post.Body) == body {
+			count++
+		}
+	}
+	if count < d.Max {
+		return "", false
+	}
+	return fmt.Sprintf("Same chirp posted %d times within %s", count+1, d.Window), true
+}
+
+// posted reports whether post was created between since and until.
+// Chirps posted after the one being checked, which an edited chirp has,
+// never count against it.
+func posted(post Post, since, until time.Time) bool {
+	return !post.CreatedAt.Before(since) && !post.CreatedAt.After(until)
+}
+
+func normalize(body string) string {
+	return strings.Join(strings.Fields(strings.ToLower(body)), " ")
+}
+
+// Links flags a chirp with more than Max links. Links are counted the
+// way they are saved, with entities.URLs.
+type Links struct {
+	Max int
+}
+
+func (l Links) Rule() string { return "links" }
+
+func (l Links) Check(in Input) (string, bool) {
+	count := len(entities.URLs(in.Chirp.Body))
+	if count <= l.Max {
+		return "", false
+	}
+	return fmt.Sprintf("Chirp has %d links, at most %d are allowed", count, l.Max), true
+}
+
+// Burst flags a chirp when the author posted Max chirps within Window
+// before it.
+type Burst struct {
+	Max    int
+	Window time.Duration
+}
+
+func (b Burst) Rule() string { return "burst" }
+
+func (b Burst) Lookback() time.Duration { return b.Window }
+
+func (b Burst) Check(in Input) (string, bool) {
+	since := in.Chirp.CreatedAt.Add(-b.Window)
+	count := 0
+	for _, post := range in.Recent {
+		if posted(post, since, in.Chirp.CreatedAt) {
+			count++
+		}
+	}
+	if count < b.Max {
+		return "", false
+	}
+	return fmt.Sprintf("%d chirps posted within %s", count+1, b.Window), true
+}
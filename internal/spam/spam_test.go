@@ -0,0 +1,140 @@
+package spam
+
+import (
+	"testing"
+	"time"
+)
+
+var now = time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+
+func post(body string, ago time.Duration) Post {
+	return Post{Body: body, CreatedAt: now.Add(-ago)}
+}
+
+func TestCheckers(t *testing.T) {
+	tests := []struct {
+		name    string
+		checker Checker
+		in      Input
+		want    bool
+	}{
+		{
+			name:    "Duplicates under the limit",
+			checker: Duplicates{Max: 2, Window: time.Hour},
+			in: Input{
+				Chirp:  post("Buy now", 0),
+				Recent: []Post{post("buy  NOW", time.Minute)},
+			},
+			want: false,
+		},
+		{
+			name:    "Duplicates at the limit",
+			checker: Duplicates{Max: 2, Window: time.Hour},
+			in: Input{
+				Chirp:  post("Buy now", 0),
+				Recent: []Post{post("buy  NOW", time.Minute), post("Buy now", 2*time.Minute)},
+			},
+			want: true,
+		},
+		{
+			name:    "Duplicates outside the window don't count",
+			checker: Duplicates{Max: 2, Window: time.Hour},
+			in: Input{
+				Chirp:  post("Buy now", 0),
+				Recent: []Post{post("Buy now", time.Minute), post("Buy now", 2*time.Hour)},
+			},
+			want: false,
+		},
+		{
+			name:    "Links under the limit",
+			checker: Links{Max: 2},
+			in:      Input{Chirp: post("See https://a.example and www.b.example", 0)},
+			want:    false,
+		},
+		{
+			name:    "Links over the limit",
+			checker: Links{Max: 2},
+			in:      Input{Chirp: post("https://a.example http://b.example https://c.example", 0)},
+			want:    true,
+		},
+		{
+			name:    "Bare www. is not a link",
+			checker: Links{Max: 1},
+			in:      Input{Chirp: post("https://a.example www.b.example www.c.example", 0)},
+			want:    false,
+		},
+		{
+			name:    "Burst under the limit",
+			checker: Burst{Max: 3, Window: time.Minute},
+			in: Input{
+				Chirp:  post("c", 0),
+				Recent: []Post{post("b", 10*time.Second), post("a", 20*time.Second), post("z", 2*time.Minute)},
+			},
+			want: false,
+		},
+		{
+			name:    "Burst at the limit",
+			checker: Burst{Max: 3, Window: time.Minute},
+			in: Input{
+				Chirp:  post("d", 0),
+				Recent: []Post{post("c", 10*time.Second), post("b", 20*time.Second), post("a", 30*time.Second)},
+			},
+			want: true,
+		},
+		{
+			name:    "Edited chirp ignores later chirps",
+			checker: Burst{Max: 3, Window: time.Minute},
+			in: Input{
+				Chirp:  post("a", 0),
+				Recent: []Post{post("d", -30*time.Second), post("c", -20*time.Second), post("b", -10*time.Second)},
+			},
+			want: false,
+		},
+		{
+			name:    "Edited chirp ignores later duplicates",
+			checker: Duplicates{Max: 1, Window: time.Hour},
+			in: Input{
+				Chirp:  post("Hello", 0),
+				Recent: []Post{post("hello", -time.Minute)},
+			},
+			want: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reason, got := tt.checker.Check(tt.in)
+			if got != tt.want {
+				t.Errorf("Check() = %v, want %v", got, tt.want)
+			}
+			if got && reason == "" {
+				t.Errorf("Check() flagged without a reason")
+			}
+		})
+	}
+}
+
+func TestPipeline(t *testing.T) {
+	p := NewPipeline(
+		Links{Max: 1},
+		Duplicates{Max: 1, Window: time.Hour},
+		Burst{Max: 5, Window: time.Minute},
+	)
+	if got := p.Lookback(); got != time.Hour {
+		t.Errorf("Lookback() = %s, want %s", got, time.Hour)
+	}
+
+	in := Input{
+		Chirp:  post("Hello", 0),
+		Recent: []Post{post("hello", time.Minute)},
+	}
+	decision, flagged := p.Check(in)
+	if !flagged || decision.Rule != "duplicates" {
+		t.Errorf("Check() = %+v, %v, want the duplicates rule", decision, flagged)
+	}
+
+	in.Recent = nil
+	if decision, flagged := p.Check(in); flagged {
+		t.Errorf("Check() = %+v, want no decision", decision)
+	}
+}
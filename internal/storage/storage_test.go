@@ -0,0 +1,71 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLocal(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	local, err := NewLocal(dir, "/media/")
+	if err != nil {
+		t.Fatalf("NewLocal() error = %v", err)
+	}
+
+	if err := local.Put(ctx, "chirps/image.png", []byte("data")); err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+	got, err := os.ReadFile(filepath.Join(dir, "chirps", "image.png"))
+	if err != nil {
+		t.Fatalf("ReadFile() error = %v", err)
+	}
+	if string(got) != "data" {
+		t.Errorf("stored data = %q, want %q", got, "data")
+	}
+
+	f, err := local.Open(ctx, "chirps/image.png")
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	got, err = io.ReadAll(f)
+	f.Close()
+	if err != nil || string(got) != "data" {
+		t.Errorf("Open() read %q, %v, want %q", got, err, "data")
+	}
+	if _, err := local.Open(ctx, "chirps"); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("Open() of a directory error = %v, want %v", err, os.ErrNotExist)
+	}
+
+	if url := local.URL("chirps/image.png"); url != "/media/chirps/image.png" {
+		t.Errorf("URL() = %q, want %q", url, "/media/chirps/image.png")
+	}
+
+	if err := local.Delete(ctx, "chirps/image.png"); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	if err := local.Delete(ctx, "chirps/image.png"); err != nil {
+		t.Errorf("Delete() of a missing key error = %v", err)
+	}
+	if _, err := local.Open(ctx, "chirps/image.png"); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("Open() of a missing key error = %v, want %v", err, os.ErrNotExist)
+	}
+}
+
+func TestLocalRejectsKeysOutsideDir(t *testing.T) {
+	local, err := NewLocal(t.TempDir(), "/media")
+	if err != nil {
+		t.Fatalf("NewLocal() error = %v", err)
+	}
+
+	for _, key := range []string{"../escape.png", "/etc/passwd", ""} {
+		err := local.Put(context.Background(), key, []byte("data"))
+		if !errors.Is(err, ErrInvalidKey) {
+			t.Errorf("Put(%q) error = %v, want %v", key, err, ErrInvalidKey)
+		}
+	}
+}
@@ -0,0 +1,79 @@
+package main
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/DanilShapilov/chirpy/internal/database"
+	"github.com/DanilShapilov/chirpy/internal/linkpreview"
+)
+
+const (
+	// linkPreviewQueueSize bounds the links waiting to be fetched. Links
+	// queued while it's full are dropped and fetched the next time they
+	// are posted.
+	linkPreviewQueueSize = 256
+	// linkPreviewMaxAge is how long a fetched preview, or a failed
+	// fetch, is kept before the link is fetched again.
+	linkPreviewMaxAge = 24 * time.Hour
+	// linkPreviewWorkers is how many links are fetched at once.
+	linkPreviewWorkers = 4
+)
+
+// queueLinkPreviews hands the links of a saved chirp to the fetcher. It
+// never blocks the request.
+func (cfg *apiConfig) queueLinkPreviews(urls []string) {
+	for _, url := range urls {
+		select {
+		case cfg.linkPreviewQueue <- url:
+		default:
+			log.Printf("Link preview queue is full, dropping %s", url)
+		}
+	}
+}
+
+// runLinkPreviewFetcher fetches the previews of queued links. It blocks
+// until ctx is done. Several of them can share the queue.
+func (cfg *apiConfig) runLinkPreviewFetcher(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case url := <-cfg.linkPreviewQueue:
+			cfg.fetchLinkPreview(ctx, url)
+		}
+	}
+}
+
+func (cfg *apiConfig) fetchLinkPreview(ctx context.Context, url string) {
+	stale, err := cfg.db.GetStaleLinkPreviewURLs(ctx, database.GetStaleLinkPreviewURLsParams{
+		Urls:         []string{url},
+		FetchedAfter: time.Now().Add(-linkPreviewMaxAge).UTC(),
+	})
+	if err != nil {
+		log.Printf("Couldn't check link preview of %s: %s", url, err)
+		return
+	}
+	if len(stale) == 0 {
+		return
+	}
+
+	preview, err := cfg.linkPreviews.Fetch(ctx, url)
+	ok := err == nil && preview != (linkpreview.Preview{})
+	if err != nil {
+		log.Printf("Couldn't fetch link preview of %s: %s", url, err)
+	}
+
+	err = cfg.db.UpsertLinkPreview(ctx, database.UpsertLinkPreviewParams{
+		Url:         url,
+		Ok:          ok,
+		Title:       preview.Title,
+		Description: preview.Description,
+		ImageUrl:    preview.ImageURL,
+		SiteName:    preview.SiteName,
+	})
+	if err != nil {
+		log.Printf("Couldn't save link preview of %s: %s", url, err)
+	}
+}
@@ -1,13 +1,24 @@
 package main
 
 import (
+	"context"
 	"database/sql"
+	"errors"
+	"fmt"
 	"log"
 	"net/http"
 	"os"
+	"strconv"
+	"strings"
 	"sync/atomic"
+	"time"
 
+	"github.com/DanilShapilov/chirpy/internal/auth"
 	"github.com/DanilShapilov/chirpy/internal/database"
+	"github.com/DanilShapilov/chirpy/internal/linkpreview"
+	"github.com/DanilShapilov/chirpy/internal/profanity"
+	"github.com/DanilShapilov/chirpy/internal/spam"
+	"github.com/DanilShapilov/chirpy/internal/storage"
 	"github.com/joho/godotenv"
 	_ "github.com/lib/pq"
 )
@@ -15,9 +26,27 @@ import (
 type apiConfig struct {
 	fileserverHits atomic.Int32
 	db             *database.Queries
+	dbConn         *sql.DB
 	platform       string
-	jwtSecret      string
+	jwtKeys        *auth.KeySet
+	accessTokens   *accessTokenRevocations
 	polkaKey       string
+
+	profanity      *profanity.Filter
+	profanityWords []string
+
+	chirpRestoreWindow time.Duration
+	chirpRetention     time.Duration
+
+	storage storage.Storage
+
+	spam *spam.Pipeline
+	// reportHideThreshold is how many open reports hide a chirp until
+	// a moderator looks at it. Zero turns it off.
+	reportHideThreshold int
+
+	linkPreviews     *linkpreview.Client
+	linkPreviewQueue chan string
 }
 
 func main() {
@@ -30,51 +59,189 @@ func main() {
 	if platform == "" {
 		log.Fatal("PLATFORM must be set")
 	}
-	jwtSecret := os.Getenv("JWT_SECRET")
-	if platform == "" {
-		log.Fatal("JWT_SECRET environment variable set")
+	jwtKeys, err := jwtKeysFromEnv()
+	if err != nil {
+		log.Fatal(err)
 	}
 	polkaKey := os.Getenv("POLKA_KEY")
 	if platform == "" {
 		log.Fatal("POLKA_KEY environment variable set")
 	}
 
+	chirpRestoreWindow, err := durationFromEnv("CHIRP_RESTORE_WINDOW", 7*24*time.Hour)
+	if err != nil {
+		log.Fatal(err)
+	}
+	chirpRetention, err := durationFromEnv("CHIRP_RETENTION", 30*24*time.Hour)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if chirpRetention < chirpRestoreWindow {
+		log.Fatal("CHIRP_RETENTION must not be shorter than CHIRP_RESTORE_WINDOW")
+	}
+
+	spamPipeline, err := spamPipelineFromEnv()
+	if err != nil {
+		log.Fatal(err)
+	}
+	reportHideThreshold, err := intFromEnv("REPORT_HIDE_THRESHOLD", 5)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	// LINK_PREVIEW_ALLOWLIST lists addresses link previews may be
+	// fetched from even though they aren't public.
+	linkPreviewAllowlist, err := linkpreview.ParseAllowlist(os.Getenv("LINK_PREVIEW_ALLOWLIST"))
+	if err != nil {
+		log.Fatalf("LINK_PREVIEW_ALLOWLIST: %v", err)
+	}
+
 	dbConn, err := sql.Open("postgres", dbURL)
 	if err != nil {
 		log.Fatalf("Unable to access DB: %v", err)
 	}
 	dbQueries := database.New(dbConn)
+	accessTokens := newAccessTokenRevocations(dbQueries)
+	jwtKeys.SetRevocationChecker(accessTokens)
+
+	profanityWords := profanity.DefaultWords
+	if path := os.Getenv("PROFANITY_WORDS_FILE"); path != "" {
+		profanityWords, err = profanity.LoadWordsFile(path)
+		if err != nil {
+			log.Fatalf("Unable to load profanity words: %v", err)
+		}
+	}
+	profanityFilter := profanity.New(profanityWords, profanity.Options{
+		Mask: os.Getenv("PROFANITY_MASK"),
+		Leet: os.Getenv("PROFANITY_LEET") == "true",
+	})
 
 	const filepathRoot = "."
 	const port = "8080"
 
+	mediaDir := os.Getenv("MEDIA_DIR")
+	if mediaDir == "" {
+		mediaDir = "./media"
+	}
+	mediaStorage, err := storage.NewLocal(mediaDir, "/media")
+	if err != nil {
+		log.Fatalf("Unable to prepare media storage: %v", err)
+	}
+
 	cfg := apiConfig{
 		fileserverHits: atomic.Int32{},
 		db:             dbQueries,
+		dbConn:         dbConn,
 		platform:       platform,
-		jwtSecret:      jwtSecret,
+		jwtKeys:        jwtKeys,
+		accessTokens:   accessTokens,
 		polkaKey:       polkaKey,
+		profanity:      profanityFilter,
+		profanityWords: profanityWords,
+
+		chirpRestoreWindow: chirpRestoreWindow,
+		chirpRetention:     chirpRetention,
+
+		storage: mediaStorage,
+
+		spam:                spamPipeline,
+		reportHideThreshold: reportHideThreshold,
+
+		linkPreviews:     linkpreview.New(linkpreview.Options{Allow: linkPreviewAllowlist}),
+		linkPreviewQueue: make(chan string, linkPreviewQueueSize),
+	}
+	if err := cfg.reloadBannedWords(context.Background()); err != nil {
+		log.Printf("Unable to load banned words from DB: %v", err)
+	}
+
+	go cfg.runBannedWordsReloader(context.Background(), time.Minute)
+	go cfg.runChirpPurger(context.Background(), time.Hour)
+	go cfg.runRevokedAccessTokenPurger(context.Background(), time.Hour)
+	go cfg.runChirpPublisher(context.Background(), 30*time.Second)
+	for range linkPreviewWorkers {
+		go cfg.runLinkPreviewFetcher(context.Background())
 	}
 
 	mux := http.NewServeMux()
 	mux.Handle("/app/", cfg.middlewareMetricsInc(http.StripPrefix("/app", http.FileServer(http.Dir(filepathRoot)))))
+	mux.HandleFunc("GET /media/{key...}", cfg.handlerMediaGet)
 
 	mux.HandleFunc("GET /api/healthz", handleReadiness)
+	mux.HandleFunc("GET /.well-known/jwks.json", cfg.handlerJWKS)
 
 	mux.HandleFunc("GET /admin/metrics", cfg.handleMetrics)
 	mux.HandleFunc("POST /admin/reset", cfg.handleReset)
+	mux.HandleFunc("GET /admin/banned-words", cfg.handlerBannedWordsList)
+	mux.HandleFunc("POST /admin/banned-words", cfg.handlerBannedWordsCreate)
+	mux.HandleFunc("DELETE /admin/banned-words/{word}", cfg.handlerBannedWordsDelete)
+	mux.HandleFunc("GET /admin/reports", cfg.handlerAdminReportsList)
+	mux.HandleFunc("POST /admin/reports/{reportID}/dismiss", cfg.handlerAdminReportsDismiss)
+	mux.HandleFunc("POST /admin/chirps/{chirpID}/hide", cfg.handlerAdminChirpsHide)
+	mux.HandleFunc("POST /admin/chirps/{chirpID}/restore", cfg.handlerAdminChirpsRestore)
+	mux.HandleFunc("POST /admin/users/{userID}/suspension", cfg.handlerAdminSuspensionCreate)
+	mux.HandleFunc("DELETE /admin/users/{userID}/suspension", cfg.handlerAdminSuspensionDelete)
+	mux.HandleFunc("GET /admin/appeals", cfg.handlerAdminAppealsList)
+	mux.HandleFunc("POST /admin/appeals/{decisionID}/accept", cfg.handlerAdminAppealsAccept)
+	mux.HandleFunc("POST /admin/appeals/{decisionID}/reject", cfg.handlerAdminAppealsReject)
+	mux.HandleFunc("GET /admin/audit-log", cfg.handlerAdminAuditLog)
 
 	mux.HandleFunc("POST /api/login", cfg.handlerLogin)
 	mux.HandleFunc("POST /api/refresh", cfg.handlerRefresh)
 	mux.HandleFunc("POST /api/revoke", cfg.handlerRevoke)
+	mux.HandleFunc("GET /api/sessions", cfg.handlerSessionsList)
+	mux.HandleFunc("DELETE /api/sessions/{sessionID}", cfg.handlerSessionsDelete)
+	mux.HandleFunc("POST /api/sessions/revoke-all", cfg.handlerSessionsRevokeAll)
 
 	mux.HandleFunc("POST /api/users", cfg.handlerUsersCreate)
 	mux.HandleFunc("PUT /api/users", cfg.handlerUsersUpdate)
+	mux.HandleFunc("POST /api/users/{userID}/follow", cfg.handlerFollowCreate)
+	mux.HandleFunc("DELETE /api/users/{userID}/follow", cfg.handlerFollowDelete)
+	mux.HandleFunc("POST /api/users/{userID}/block", cfg.handlerBlockCreate)
+	mux.HandleFunc("DELETE /api/users/{userID}/block", cfg.handlerBlockDelete)
+	mux.HandleFunc("POST /api/users/{userID}/mute", cfg.handlerMuteCreate)
+	mux.HandleFunc("DELETE /api/users/{userID}/mute", cfg.handlerMuteDelete)
+
+	mux.HandleFunc("GET /api/timeline", cfg.handlerTimeline)
 
 	mux.HandleFunc("POST /api/chirps", cfg.handlerChirpsCreate)
 	mux.HandleFunc("GET /api/chirps", cfg.handlerChirpsList)
+	mux.HandleFunc("GET /api/chirps/search", cfg.handlerChirpsSearch)
+	mux.HandleFunc("GET /api/chirps/scheduled", cfg.handlerScheduledChirpsList)
+	mux.HandleFunc("DELETE /api/chirps/scheduled", cfg.handlerScheduledChirpsDelete)
 	mux.HandleFunc("GET /api/chirps/{chirpID}", cfg.handlerChirpsGet)
+	mux.HandleFunc("PUT /api/chirps/{chirpID}", cfg.handlerChirpsUpdate)
+	mux.HandleFunc("GET /api/chirps/{chirpID}/revisions", cfg.handlerChirpRevisionsList)
+	mux.HandleFunc("GET /api/chirps/{chirpID}/thread", cfg.handlerChirpsThread)
+	mux.HandleFunc("POST /api/chirps/{chirpID}/likes", cfg.handlerChirpLikesCreate)
+	mux.HandleFunc("DELETE /api/chirps/{chirpID}/likes", cfg.handlerChirpLikesDelete)
+	mux.HandleFunc("GET /api/chirps/{chirpID}/likes", cfg.handlerChirpLikesList)
 	mux.HandleFunc("DELETE /api/chirps/{chirpID}", cfg.handlerChirpsDelete)
+	mux.HandleFunc("POST /api/chirps/{chirpID}/restore", cfg.handlerChirpsRestore)
+	mux.HandleFunc("POST /api/chirps/{chirpID}/rechirp", cfg.handlerChirpsRechirp)
+	mux.HandleFunc("POST /api/chirps/{chirpID}/reports", cfg.handlerReportsCreate)
+
+	mux.HandleFunc("POST /api/chirps/{chirpID}/poll/votes", cfg.handlerPollVotesCreate)
+
+	mux.HandleFunc("POST /api/chirps/{chirpID}/bookmark", cfg.handlerBookmarksCreate)
+	mux.HandleFunc("DELETE /api/chirps/{chirpID}/bookmark", cfg.handlerBookmarksDelete)
+	mux.HandleFunc("GET /api/bookmarks", cfg.handlerBookmarksList)
+
+	mux.HandleFunc("POST /api/collections", cfg.handlerCollectionsCreate)
+	mux.HandleFunc("GET /api/collections", cfg.handlerCollectionsList)
+	mux.HandleFunc("PUT /api/collections/{collectionID}", cfg.handlerCollectionsUpdate)
+	mux.HandleFunc("DELETE /api/collections/{collectionID}", cfg.handlerCollectionsDelete)
+
+	mux.HandleFunc("POST /api/drafts", cfg.handlerDraftsCreate)
+	mux.HandleFunc("GET /api/drafts", cfg.handlerDraftsList)
+	mux.HandleFunc("PUT /api/drafts/{draftID}", cfg.handlerDraftsUpdate)
+	mux.HandleFunc("DELETE /api/drafts/{draftID}", cfg.handlerDraftsDelete)
+	mux.HandleFunc("POST /api/drafts/{draftID}/publish", cfg.handlerDraftsPublish)
+
+	mux.HandleFunc("GET /api/moderation/decisions", cfg.handlerDecisionsList)
+	mux.HandleFunc("POST /api/moderation/decisions/{decisionID}/appeal", cfg.handlerDecisionsAppeal)
+
+	mux.HandleFunc("GET /api/tags/trending", cfg.handlerTagsTrending)
+	mux.HandleFunc("GET /api/tags/{tag}/chirps", cfg.handlerTagChirps)
 
 	mux.HandleFunc("POST /api/polka/webhooks", cfg.handlerWebhook)
 
@@ -87,3 +254,70 @@ func main() {
 	log.Fatal(server.ListenAndServe())
 
 }
+
+// jwtKeysFromEnv loads the keys access tokens are signed with.
+// JWT_SIGNING_KEY_FILE is a PEM encoded RSA or Ed25519 private key and
+// JWT_VERIFICATION_KEY_FILES a comma separated list of keys that are
+// still accepted, such as the previous signing key during a rotation.
+// JWT_SECRET signs tokens with HS256 when there's no signing key. Once
+// there is one, JWT_ACCEPT_LEGACY_HS256=true keeps accepting tokens
+// signed with JWT_SECRET while moving over; turn it off an hour later.
+func jwtKeysFromEnv() (*auth.KeySet, error) {
+	secret := os.Getenv("JWT_SECRET")
+	path := os.Getenv("JWT_SIGNING_KEY_FILE")
+	if path == "" {
+		if secret == "" {
+			return nil, errors.New("JWT_SIGNING_KEY_FILE or JWT_SECRET must be set")
+		}
+		return auth.NewKeySet(auth.HMACKey(secret))
+	}
+
+	signing, err := auth.LoadKeyFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("JWT_SIGNING_KEY_FILE: %w", err)
+	}
+	var verification []*auth.Key
+	for _, path := range strings.Split(os.Getenv("JWT_VERIFICATION_KEY_FILES"), ",") {
+		path = strings.TrimSpace(path)
+		if path == "" {
+			continue
+		}
+		key, err := auth.LoadKeyFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("JWT_VERIFICATION_KEY_FILES: %w", err)
+		}
+		verification = append(verification, key)
+	}
+	if os.Getenv("JWT_ACCEPT_LEGACY_HS256") == "true" {
+		if secret == "" {
+			return nil, errors.New("JWT_ACCEPT_LEGACY_HS256 needs JWT_SECRET")
+		}
+		log.Print("Warning: JWT_ACCEPT_LEGACY_HS256 is on, anyone with JWT_SECRET can sign access tokens. Turn it off once the old tokens have expired.")
+		verification = append(verification, auth.HMACKey(secret))
+	}
+	return auth.NewKeySet(signing, verification...)
+}
+
+func durationFromEnv(key string, fallback time.Duration) (time.Duration, error) {
+	value := os.Getenv(key)
+	if value == "" {
+		return fallback, nil
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		return 0, fmt.Errorf("%s must be a duration: %w", key, err)
+	}
+	return d, nil
+}
+
+func intFromEnv(key string, fallback int) (int, error) {
+	value := os.Getenv(key)
+	if value == "" {
+		return fallback, nil
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("%s must be a non-negative integer", key)
+	}
+	return n, nil
+}
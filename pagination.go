@@ -0,0 +1,115 @@
+package main
+
+import (
+	"encoding/base64"
+	"errors"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/DanilShapilov/chirpy/internal/database"
+	"github.com/google/uuid"
+)
+
+const (
+	defaultPageLimit = 20
+	maxPageLimit     = 100
+)
+
+// ChirpsPage is the response body of every paginated chirp feed.
+type ChirpsPage struct {
+	Chirps     []Chirp `json:"chirps"`
+	NextCursor *string `json:"next_cursor"`
+}
+
+// pageCursor points at the last row of a page. It is handed to clients
+// as an opaque string and only ever compared with (created_at, id).
+type pageCursor struct {
+	CreatedAt time.Time
+	ID        uuid.UUID
+}
+
+func encodeCursor(createdAt time.Time, id uuid.UUID) string {
+	raw := createdAt.UTC().Format(time.RFC3339Nano) + "|" + id.String()
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+func decodeCursor(s string) (pageCursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return pageCursor{}, errors.New("malformed cursor")
+	}
+	createdAtStr, idStr, ok := strings.Cut(string(raw), "|")
+	if !ok {
+		return pageCursor{}, errors.New("malformed cursor")
+	}
+	createdAt, err := time.Parse(time.RFC3339Nano, createdAtStr)
+	if err != nil {
+		return pageCursor{}, errors.New("malformed cursor")
+	}
+	id, err := uuid.Parse(idStr)
+	if err != nil {
+		return pageCursor{}, errors.New("malformed cursor")
+	}
+	return pageCursor{CreatedAt: createdAt, ID: id}, nil
+}
+
+// pageParams holds the parsed `limit` and `cursor` query parameters.
+// Paginated is false when the client sent neither of them.
+type pageParams struct {
+	Paginated bool
+	Limit     int32
+	Cursor    *pageCursor
+}
+
+func parsePageParams(query url.Values) (pageParams, error) {
+	limitStr := query.Get("limit")
+	cursorStr := query.Get("cursor")
+	if limitStr == "" && cursorStr == "" {
+		return pageParams{}, nil
+	}
+
+	limit, err := parseLimit(query)
+	if err != nil {
+		return pageParams{}, err
+	}
+	params := pageParams{
+		Paginated: true,
+		Limit:     limit,
+	}
+	if cursorStr != "" {
+		cursor, err := decodeCursor(cursorStr)
+		if err != nil {
+			return pageParams{}, err
+		}
+		params.Cursor = &cursor
+	}
+	return params, nil
+}
+
+// parseLimit reads the `limit` query parameter, falling back to
+// defaultPageLimit when it is absent.
+func parseLimit(query url.Values) (int32, error) {
+	limitStr := query.Get("limit")
+	if limitStr == "" {
+		return defaultPageLimit, nil
+	}
+	limit, err := strconv.Atoi(limitStr)
+	if err != nil || limit < 1 || limit > maxPageLimit {
+		return 0, errors.New("limit must be between 1 and " + strconv.Itoa(maxPageLimit))
+	}
+	return int32(limit), nil
+}
+
+// trimChirpsPage cuts a result fetched with limit+1 rows down to limit
+// and returns the cursor of the next page, or nil on the last page.
+func trimChirpsPage(chirps []database.Chirp, limit int32) ([]database.Chirp, *string) {
+	if len(chirps) <= int(limit) {
+		return chirps, nil
+	}
+	chirps = chirps[:limit]
+	last := chirps[len(chirps)-1]
+	cursor := encodeCursor(last.CreatedAt, last.ID)
+	return chirps, &cursor
+}
@@ -0,0 +1,178 @@
+package main
+
+import (
+	"encoding/base64"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/DanilShapilov/chirpy/internal/database"
+	"github.com/google/uuid"
+)
+
+func TestCursorRoundTrip(t *testing.T) {
+	createdAt := time.Date(2024, 5, 1, 12, 30, 0, 123456789, time.FixedZone("CEST", 2*60*60))
+	id := uuid.New()
+
+	got, err := decodeCursor(encodeCursor(createdAt, id))
+	if err != nil {
+		t.Fatalf("decodeCursor() error = %v", err)
+	}
+	if !got.CreatedAt.Equal(createdAt) || got.ID != id {
+		t.Errorf("decodeCursor() = %+v, want %v and %v", got, createdAt, id)
+	}
+}
+
+func TestDecodeCursor(t *testing.T) {
+	valid := encodeCursor(time.Now(), uuid.New())
+	encode := func(raw string) string {
+		return base64.RawURLEncoding.EncodeToString([]byte(raw))
+	}
+
+	tests := []struct {
+		name    string
+		cursor  string
+		wantErr bool
+	}{
+		{
+			name:   "Valid cursor",
+			cursor: valid,
+		},
+		{
+			name:    "Not base64",
+			cursor:  "not a cursor!",
+			wantErr: true,
+		},
+		{
+			name:    "Truncated",
+			cursor:  valid[:len(valid)-4],
+			wantErr: true,
+		},
+		{
+			name:    "No separator",
+			cursor:  encode("2024-05-01T12:30:00Z"),
+			wantErr: true,
+		},
+		{
+			name:    "Tampered time",
+			cursor:  encode("yesterday|" + uuid.NewString()),
+			wantErr: true,
+		},
+		{
+			name:    "Tampered ID",
+			cursor:  encode("2024-05-01T12:30:00Z|1 OR 1=1"),
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := decodeCursor(tt.cursor)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("decodeCursor() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestParsePageParams(t *testing.T) {
+	createdAt := time.Date(2024, 5, 1, 12, 30, 0, 0, time.UTC)
+	id := uuid.New()
+	cursor := encodeCursor(createdAt, id)
+
+	tests := []struct {
+		name    string
+		query   url.Values
+		want    pageParams
+		wantErr bool
+	}{
+		{
+			name:  "Not paginated",
+			query: url.Values{},
+			want:  pageParams{},
+		},
+		{
+			name:  "Default limit",
+			query: url.Values{"cursor": {cursor}},
+			want:  pageParams{Paginated: true, Limit: defaultPageLimit, Cursor: &pageCursor{CreatedAt: createdAt, ID: id}},
+		},
+		{
+			name:  "Limit without cursor",
+			query: url.Values{"limit": {"5"}},
+			want:  pageParams{Paginated: true, Limit: 5},
+		},
+		{
+			name:  "Maximum limit",
+			query: url.Values{"limit": {"100"}},
+			want:  pageParams{Paginated: true, Limit: maxPageLimit},
+		},
+		{
+			name:    "Limit over the maximum",
+			query:   url.Values{"limit": {"101"}},
+			wantErr: true,
+		},
+		{
+			name:    "Zero limit",
+			query:   url.Values{"limit": {"0"}},
+			wantErr: true,
+		},
+		{
+			name:    "Negative limit",
+			query:   url.Values{"limit": {"-1"}},
+			wantErr: true,
+		},
+		{
+			name:    "Limit is not a number",
+			query:   url.Values{"limit": {"ten"}},
+			wantErr: true,
+		},
+		{
+			name:    "Tampered cursor",
+			query:   url.Values{"limit": {"5"}, "cursor": {cursor + "x"}},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parsePageParams(tt.query)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parsePageParams() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got.Paginated != tt.want.Paginated || got.Limit != tt.want.Limit {
+				t.Errorf("parsePageParams() = %+v, want %+v", got, tt.want)
+			}
+			if (got.Cursor == nil) != (tt.want.Cursor == nil) {
+				t.Fatalf("parsePageParams() cursor = %v, want %v", got.Cursor, tt.want.Cursor)
+			}
+			if got.Cursor != nil && (!got.Cursor.CreatedAt.Equal(tt.want.Cursor.CreatedAt) || got.Cursor.ID != tt.want.Cursor.ID) {
+				t.Errorf("parsePageParams() cursor = %+v, want %+v", *got.Cursor, *tt.want.Cursor)
+			}
+		})
+	}
+}
+
+func TestTrimChirpsPage(t *testing.T) {
+	start := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	chirps := make([]database.Chirp, 3)
+	for i := range chirps {
+		chirps[i] = database.Chirp{ID: uuid.New(), CreatedAt: start.Add(time.Duration(i) * time.Minute)}
+	}
+
+	page, next := trimChirpsPage(chirps, 3)
+	if len(page) != 3 || next != nil {
+		t.Errorf("trimChirpsPage(last page) = %d chirps, cursor %v, want 3 and nil", len(page), next)
+	}
+
+	page, next = trimChirpsPage(chirps, 2)
+	if len(page) != 2 || next == nil {
+		t.Fatalf("trimChirpsPage() = %d chirps, cursor %v, want 2 and a cursor", len(page), next)
+	}
+	cursor, err := decodeCursor(*next)
+	if err != nil {
+		t.Fatalf("decodeCursor(next) error = %v", err)
+	}
+	if cursor.ID != chirps[1].ID || !cursor.CreatedAt.Equal(chirps[1].CreatedAt) {
+		t.Errorf("next cursor = %+v, want the last chirp of the page", cursor)
+	}
+}
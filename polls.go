@@ -0,0 +1,163 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/DanilShapilov/chirpy/internal/database"
+	"github.com/google/uuid"
+)
+
+const (
+	minPollOptions      = 2
+	maxPollOptions      = 4
+	maxPollOptionLength = 25
+	maxPollDuration     = 7 * 24 * time.Hour
+)
+
+type ChirpPoll struct {
+	ExpiresAt  time.Time         `json:"expires_at"`
+	Closed     bool              `json:"closed"`
+	Options    []ChirpPollOption `json:"options"`
+	TotalVotes int64             `json:"total_votes"`
+	// MyVote is the position of the option the viewer voted for.
+	MyVote *int32 `json:"my_vote"`
+}
+
+type ChirpPollOption struct {
+	Position int32  `json:"position"`
+	Text     string `json:"text"`
+	Votes    int64  `json:"votes"`
+}
+
+var errPollHasVotes = errors.New("Couldn't edit a poll that has votes")
+
+// pollParams is the `poll` field of POST and PUT /api/chirps.
+type pollParams struct {
+	Options   []string  `json:"options"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// validatePoll checks the options and the expiry of a poll and returns
+// it with the options trimmed. A scheduled chirp's poll has to outlive
+// its publication.
+func validatePoll(poll pollParams, publishAt sql.NullTime) (pollParams, error) {
+	if len(poll.Options) < minPollOptions || len(poll.Options) > maxPollOptions {
+		return pollParams{}, errors.New("Poll must have between 2 and 4 options")
+	}
+	options := make([]string, len(poll.Options))
+	for i, option := range poll.Options {
+		option = strings.TrimSpace(option)
+		if option == "" {
+			return pollParams{}, errors.New("Poll options can't be empty")
+		}
+		if utf8.RuneCountInString(option) > maxPollOptionLength {
+			return pollParams{}, errors.New("Poll option is too long")
+		}
+		options[i] = option
+	}
+
+	opensAt := time.Now()
+	if publishAt.Valid {
+		opensAt = publishAt.Time
+	}
+	if !poll.ExpiresAt.After(opensAt) {
+		return pollParams{}, errors.New("Poll must expire after it opens")
+	}
+	if poll.ExpiresAt.Sub(opensAt) > maxPollDuration {
+		return pollParams{}, errors.New("Poll can't run longer than 7 days")
+	}
+
+	return pollParams{
+		Options:   options,
+		ExpiresAt: poll.ExpiresAt.UTC(),
+	}, nil
+}
+
+func savePoll(ctx context.Context, q *database.Queries, chirpID uuid.UUID, poll pollParams) error {
+	err := q.CreatePoll(ctx, database.CreatePollParams{
+		ChirpID:   chirpID,
+		ExpiresAt: poll.ExpiresAt,
+	})
+	if err != nil {
+		return err
+	}
+	return q.CreatePollOptions(ctx, database.CreatePollOptionsParams{
+		ChirpID: chirpID,
+		Texts:   poll.Options,
+	})
+}
+
+// replacePoll swaps the poll of a chirp for a new one, or adds one if
+// the chirp had none. It fails with errPollHasVotes once anyone voted.
+// The poll row is locked first, so no vote can slip in meanwhile.
+func replacePoll(ctx context.Context, q *database.Queries, chirpID uuid.UUID, poll pollParams) error {
+	_, err := q.GetPollForUpdate(ctx, chirpID)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return err
+	}
+	if err == nil {
+		votes, err := q.CountPollVotes(ctx, chirpID)
+		if err != nil {
+			return err
+		}
+		if votes > 0 {
+			return errPollHasVotes
+		}
+		if err := q.DeletePoll(ctx, chirpID); err != nil {
+			return err
+		}
+	}
+	return savePoll(ctx, q, chirpID, poll)
+}
+
+// loadChirpPolls loads the polls of chirps with their tallies, along
+// with the viewer's votes when viewerID isn't uuid.Nil.
+func (cfg *apiConfig) loadChirpPolls(ctx context.Context, chirpIDs []uuid.UUID, viewerID uuid.UUID) (map[uuid.UUID]*ChirpPoll, error) {
+	rows, err := cfg.db.GetPollOptions(ctx, chirpIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	polls := make(map[uuid.UUID]*ChirpPoll)
+	for _, row := range rows {
+		poll, ok := polls[row.ChirpID]
+		if !ok {
+			poll = &ChirpPoll{
+				ExpiresAt: row.ExpiresAt,
+				Closed:    !row.ExpiresAt.After(now),
+				Options:   []ChirpPollOption{},
+			}
+			polls[row.ChirpID] = poll
+		}
+		poll.Options = append(poll.Options, ChirpPollOption{
+			Position: row.Position,
+			Text:     row.Text,
+			Votes:    row.VoteCount,
+		})
+		poll.TotalVotes += row.VoteCount
+	}
+
+	if viewerID == uuid.Nil || len(polls) == 0 {
+		return polls, nil
+	}
+	votes, err := cfg.db.GetPollVotesByUser(ctx, database.GetPollVotesByUserParams{
+		UserID:   viewerID,
+		ChirpIds: chirpIDs,
+	})
+	if err != nil {
+		return nil, err
+	}
+	for _, vote := range votes {
+		if poll, ok := polls[vote.ChirpID]; ok {
+			position := vote.Position
+			poll.MyVote = &position
+		}
+	}
+	return polls, nil
+}
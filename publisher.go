@@ -0,0 +1,62 @@
+package main
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/DanilShapilov/chirpy/internal/database"
+	"github.com/google/uuid"
+)
+
+// publishBatchSize caps how many chirps one statement publishes, so a
+// large backlog doesn't hold row locks for long.
+const publishBatchSize = 100
+
+// runChirpPublisher publishes scheduled chirps once their publish_at has
+// passed. It blocks until ctx is done. Due rows are claimed with FOR
+// UPDATE SKIP LOCKED, so several instances can run side by side without
+// publishing a chirp twice.
+func (cfg *apiConfig) runChirpPublisher(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		cfg.publishDueChirps(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (cfg *apiConfig) publishDueChirps(ctx context.Context) {
+	for {
+		published, err := cfg.db.PublishDueChirps(ctx, publishBatchSize)
+		if err != nil {
+			log.Printf("Couldn't publish scheduled chirps: %s", err)
+			return
+		}
+		if len(published) > 0 {
+			log.Printf("Published %d scheduled chirps", len(published))
+			cfg.restampPublishedTags(ctx, published)
+		}
+		if len(published) < publishBatchSize {
+			return
+		}
+	}
+}
+
+// restampPublishedTags dates the tags of scheduled chirps from when they
+// were published rather than scheduled, so they count as trending now.
+func (cfg *apiConfig) restampPublishedTags(ctx context.Context, published []database.Chirp) {
+	ids := make([]uuid.UUID, len(published))
+	for i, chirp := range published {
+		ids[i] = chirp.ID
+	}
+	if err := cfg.db.RestampChirpTags(ctx, ids); err != nil {
+		log.Printf("Couldn't restamp tags of published chirps: %s", err)
+	}
+}
@@ -0,0 +1,52 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"log"
+	"time"
+)
+
+// runChirpPurger hard-deletes soft-deleted chirps once cfg.chirpRetention
+// has passed. It blocks until ctx is done.
+func (cfg *apiConfig) runChirpPurger(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		cfg.purgeDeletedChirps(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (cfg *apiConfig) purgeDeletedChirps(ctx context.Context) {
+	deletedBefore := sql.NullTime{
+		Time:  time.Now().Add(-cfg.chirpRetention).UTC(),
+		Valid: true,
+	}
+
+	// Collect the files first, the rows are gone after the purge.
+	purgeableMedia, err := cfg.db.GetPurgeableChirpMedia(ctx, deletedBefore)
+	if err != nil {
+		log.Printf("Couldn't get media of deleted chirps: %s", err)
+		return
+	}
+
+	purged, err := cfg.db.PurgeDeletedChirps(ctx, deletedBefore)
+	if err != nil {
+		log.Printf("Couldn't purge deleted chirps: %s", err)
+		return
+	}
+	if purged > 0 {
+		log.Printf("Purged %d deleted chirps", purged)
+	}
+
+	for _, m := range purgeableMedia {
+		cfg.deleteMediaFiles(m.StorageKey, m.ThumbnailKey)
+	}
+}
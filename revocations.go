@@ -0,0 +1,133 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log"
+	"time"
+
+	"github.com/DanilShapilov/chirpy/internal/auth"
+	"github.com/DanilShapilov/chirpy/internal/database"
+	"github.com/DanilShapilov/chirpy/internal/lru"
+	"github.com/google/uuid"
+)
+
+const (
+	// revocationCacheSize bounds the token IDs and users whose
+	// revocation state is kept in memory.
+	revocationCacheSize = 10_000
+	// revocationCacheTTL is how long a cached answer is trusted. Tokens
+	// revoked through another instance are accepted here for at most
+	// this long.
+	revocationCacheTTL = 30 * time.Second
+)
+
+// accessTokenRevocations decides whether an access token was revoked,
+// either on its own by its jti or with every other token of its user by
+// the user's sessions_not_before. Postgres holds the state, the caches
+// save a query per request.
+type accessTokenRevocations struct {
+	db        *database.Queries
+	revoked   *lru.Cache[uuid.UUID, bool]
+	notBefore *lru.Cache[uuid.UUID, time.Time]
+}
+
+func newAccessTokenRevocations(db *database.Queries) *accessTokenRevocations {
+	return &accessTokenRevocations{
+		db:        db,
+		revoked:   lru.New[uuid.UUID, bool](revocationCacheSize, revocationCacheTTL),
+		notBefore: lru.New[uuid.UUID, time.Time](revocationCacheSize, revocationCacheTTL),
+	}
+}
+
+func (r *accessTokenRevocations) IsRevoked(ctx context.Context, token auth.AccessToken) (bool, error) {
+	notBefore, ok := r.notBefore.Get(token.UserID)
+	if !ok {
+		nb, err := r.db.GetUserSessionsNotBefore(ctx, token.UserID)
+		if errors.Is(err, sql.ErrNoRows) {
+			// The user was deleted.
+			return true, nil
+		}
+		if err != nil {
+			return false, err
+		}
+		notBefore = nb.Time
+		r.notBefore.Add(token.UserID, notBefore)
+	}
+	// iat has microseconds like sessions_not_before, so a login right
+	// after a revocation is told apart from a token issued right before
+	// it.
+	if token.IssuedAt.Before(notBefore) {
+		return true, nil
+	}
+
+	if token.ID == uuid.Nil {
+		return false, nil
+	}
+	revoked, ok := r.revoked.Get(token.ID)
+	if !ok {
+		var err error
+		revoked, err = r.db.IsAccessTokenRevoked(ctx, token.ID)
+		if err != nil {
+			return false, err
+		}
+		r.revoked.Add(token.ID, revoked)
+	}
+	return revoked, nil
+}
+
+// revoke denylists a single access token until it expires.
+func (r *accessTokenRevocations) revoke(ctx context.Context, token auth.AccessToken) error {
+	if token.ID == uuid.Nil {
+		return errors.New("access token has no ID")
+	}
+	err := r.db.RevokeAccessToken(ctx, database.RevokeAccessTokenParams{
+		Jti:       token.ID,
+		UserID:    token.UserID,
+		ExpiresAt: token.ExpiresAt.UTC(),
+	})
+	if err != nil {
+		return err
+	}
+	r.revoked.Add(token.ID, true)
+	return nil
+}
+
+// sessionsNotBeforeParams revokes every access token of a user issued
+// before now. The time is UTC like iat, and truncated to microseconds
+// like iat and the column, which would otherwise round it up.
+func sessionsNotBeforeParams(userID uuid.UUID) database.SetUserSessionsNotBeforeParams {
+	return database.SetUserSessionsNotBeforeParams{
+		SessionsNotBefore: sql.NullTime{Time: time.Now().UTC().Truncate(time.Microsecond), Valid: true},
+		ID:                userID,
+	}
+}
+
+// forgetUser drops the cached sessions_not_before of a user. Call it
+// once a change to it is committed, so the next request reads it.
+func (r *accessTokenRevocations) forgetUser(userID uuid.UUID) {
+	r.notBefore.Remove(userID)
+}
+
+// runRevokedAccessTokenPurger deletes denylisted access tokens that
+// have expired anyway. It blocks until ctx is done.
+func (cfg *apiConfig) runRevokedAccessTokenPurger(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		purged, err := cfg.db.PurgeRevokedAccessTokens(ctx)
+		if err != nil {
+			log.Printf("Couldn't purge revoked access tokens: %s", err)
+		} else if purged > 0 {
+			log.Printf("Purged %d revoked access tokens", purged)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,14 @@
+-- name: CreateBannedWord :exec
+INSERT INTO banned_words (word, created_at)
+VALUES (
+    $1,
+    NOW()
+)
+ON CONFLICT (word) DO NOTHING;
+
+-- name: GetBannedWords :many
+SELECT * FROM banned_words
+ORDER BY word ASC;
+
+-- name: DeleteBannedWord :execrows
+DELETE FROM banned_words WHERE word = $1;
\ No newline at end of file
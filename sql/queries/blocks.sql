@@ -0,0 +1,18 @@
+-- name: BlockUser :exec
+INSERT INTO blocks (blocker_id, blocked_id, created_at)
+VALUES (
+    $1,
+    $2,
+    NOW()
+)
+ON CONFLICT (blocker_id, blocked_id) DO NOTHING;
+
+-- name: UnblockUser :execrows
+DELETE FROM blocks
+WHERE blocker_id = $1 AND blocked_id = $2;
+
+-- name: IsBlockedBy :one
+SELECT EXISTS (
+    SELECT 1 FROM blocks
+    WHERE blocker_id = sqlc.arg('blocker_id') AND blocked_id = sqlc.arg('blocked_id')
+);
\ No newline at end of file
@@ -0,0 +1,35 @@
+-- name: BookmarkChirp :exec
+INSERT INTO bookmarks (user_id, chirp_id, collection_id, created_at)
+VALUES (
+    sqlc.arg('user_id'),
+    sqlc.arg('chirp_id'),
+    sqlc.narg('collection_id'),
+    NOW()
+)
+ON CONFLICT (user_id, chirp_id) DO UPDATE SET collection_id = CASE
+    WHEN sqlc.arg('keep_collection')::bool THEN bookmarks.collection_id
+    ELSE EXCLUDED.collection_id
+END;
+
+-- name: DeleteBookmark :execrows
+DELETE FROM bookmarks
+WHERE user_id = $1 AND chirp_id = $2;
+
+-- name: GetBookmarkedChirps :many
+SELECT sqlc.embed(chirps), bookmarks.created_at AS bookmarked_at FROM bookmarks
+JOIN chirps ON chirps.id = bookmarks.chirp_id
+WHERE bookmarks.user_id = sqlc.arg('user_id')
+AND chirps.deleted_at IS NULL
+AND chirps.publish_at IS NULL
+AND NOT EXISTS (
+    SELECT 1 FROM blocks
+    WHERE (blocks.blocker_id = sqlc.arg('user_id') AND blocks.blocked_id = chirps.user_id)
+    OR (blocks.blocker_id = chirps.user_id AND blocks.blocked_id = sqlc.arg('user_id'))
+)
+AND (sqlc.narg('collection_id')::uuid IS NULL OR bookmarks.collection_id = sqlc.narg('collection_id'))
+AND (
+    sqlc.narg('cursor_created_at')::timestamp IS NULL
+    OR (bookmarks.created_at, bookmarks.chirp_id) < (sqlc.narg('cursor_created_at'), sqlc.narg('cursor_id')::uuid)
+)
+ORDER BY bookmarks.created_at DESC, bookmarks.chirp_id DESC
+LIMIT sqlc.arg('limit');
\ No newline at end of file
@@ -0,0 +1,39 @@
+-- name: LikeChirp :execrows
+INSERT INTO chirp_likes (user_id, chirp_id, created_at)
+SELECT sqlc.arg('user_id')::uuid, chirps.id, NOW()
+FROM chirps
+WHERE chirps.id = sqlc.arg('chirp_id')
+AND NOT EXISTS (
+    SELECT 1 FROM blocks
+    WHERE blocks.blocker_id = chirps.user_id AND blocks.blocked_id = sqlc.arg('user_id')
+)
+ON CONFLICT (user_id, chirp_id) DO NOTHING;
+
+-- name: UnlikeChirp :execrows
+DELETE FROM chirp_likes
+WHERE user_id = $1 AND chirp_id = $2;
+
+-- name: CountChirpLikes :many
+SELECT chirp_id, COUNT(*) AS like_count FROM chirp_likes
+WHERE chirp_id = ANY(sqlc.arg('chirp_ids')::uuid[])
+AND NOT EXISTS (
+    SELECT 1 FROM blocks
+    WHERE (blocks.blocker_id = sqlc.narg('viewer_id')::uuid AND blocks.blocked_id = chirp_likes.user_id)
+    OR (blocks.blocker_id = chirp_likes.user_id AND blocks.blocked_id = sqlc.narg('viewer_id')::uuid)
+)
+GROUP BY chirp_id;
+
+-- name: GetChirpsLikedByUser :many
+SELECT chirp_id FROM chirp_likes
+WHERE user_id = sqlc.arg('user_id')
+AND chirp_id = ANY(sqlc.arg('chirp_ids')::uuid[]);
+
+-- name: GetChirpLikes :many
+SELECT * FROM chirp_likes
+WHERE chirp_id = sqlc.arg('chirp_id')
+AND NOT EXISTS (
+    SELECT 1 FROM blocks
+    WHERE (blocks.blocker_id = sqlc.narg('viewer_id')::uuid AND blocks.blocked_id = chirp_likes.user_id)
+    OR (blocks.blocker_id = chirp_likes.user_id AND blocks.blocked_id = sqlc.narg('viewer_id')::uuid)
+)
+ORDER BY created_at DESC;
\ No newline at end of file
@@ -0,0 +1,49 @@
+-- name: CreateChirpMedia :one
+INSERT INTO chirp_media (
+    id,
+    chirp_id,
+    position,
+    storage_key,
+    thumbnail_key,
+    content_type,
+    width,
+    height,
+    thumbnail_width,
+    thumbnail_height,
+    alt_text,
+    created_at
+)
+VALUES (
+    gen_random_uuid(),
+    $1,
+    $2,
+    $3,
+    $4,
+    $5,
+    $6,
+    $7,
+    $8,
+    $9,
+    $10,
+    NOW()
+)
+RETURNING *;
+
+-- name: GetChirpMedia :many
+SELECT * FROM chirp_media
+WHERE chirp_id = ANY(sqlc.arg('chirp_ids')::uuid[])
+ORDER BY chirp_id, position ASC;
+
+-- name: GetPurgeableChirpMedia :many
+SELECT chirp_media.* FROM chirp_media
+JOIN chirps ON chirps.id = chirp_media.chirp_id
+WHERE chirps.deleted_at < sqlc.arg('deleted_before') AND chirps.hidden_at IS NULL;
+
+-- name: IsChirpMediaVisible :one
+SELECT EXISTS (
+    SELECT 1 FROM chirp_media
+    JOIN chirps ON chirps.id = chirp_media.chirp_id
+    WHERE (chirp_media.storage_key = sqlc.arg('key') OR chirp_media.thumbnail_key = sqlc.arg('key'))
+    AND chirps.deleted_at IS NULL
+    AND chirps.publish_at IS NULL
+);
\ No newline at end of file
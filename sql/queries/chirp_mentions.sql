@@ -0,0 +1,18 @@
+-- name: CreateChirpMentions :exec
+INSERT INTO chirp_mentions (chirp_id, user_id)
+SELECT chirps.id, mentioned.user_id
+FROM chirps, unnest(sqlc.arg('user_ids')::uuid[]) AS mentioned(user_id)
+WHERE chirps.id = sqlc.arg('chirp_id')
+AND NOT EXISTS (
+    SELECT 1 FROM blocks
+    WHERE blocks.blocker_id = mentioned.user_id AND blocks.blocked_id = chirps.user_id
+)
+ON CONFLICT (chirp_id, user_id) DO NOTHING;
+
+-- name: DeleteChirpMentions :exec
+DELETE FROM chirp_mentions WHERE chirp_id = $1;
+
+-- name: GetChirpMentions :many
+SELECT chirp_mentions.chirp_id, users.id AS user_id, users.email FROM chirp_mentions
+JOIN users ON users.id = chirp_mentions.user_id
+WHERE chirp_mentions.chirp_id = ANY(sqlc.arg('chirp_ids')::uuid[]);
\ No newline at end of file
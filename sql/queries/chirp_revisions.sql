@@ -0,0 +1,15 @@
+-- name: CreateChirpRevision :one
+INSERT INTO chirp_revisions (id, chirp_id, created_at, replaced_at, body)
+VALUES (
+    gen_random_uuid(),
+    $1,
+    $2,
+    NOW(),
+    $3
+)
+RETURNING *;
+
+-- name: GetChirpRevisions :many
+SELECT * FROM chirp_revisions
+WHERE chirp_id = $1
+ORDER BY replaced_at ASC;
\ No newline at end of file
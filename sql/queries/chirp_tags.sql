@@ -0,0 +1,46 @@
+-- name: CreateChirpTags :exec
+INSERT INTO chirp_tags (chirp_id, tag, created_at)
+SELECT sqlc.arg('chirp_id')::uuid, unnest(sqlc.arg('tags')::text[]), sqlc.arg('created_at')
+ON CONFLICT (chirp_id, tag) DO NOTHING;
+
+-- name: RestampChirpTags :exec
+UPDATE chirp_tags
+SET created_at = chirps.created_at
+FROM chirps
+WHERE chirps.id = chirp_tags.chirp_id
+AND chirps.id = ANY(sqlc.arg('chirp_ids')::uuid[]);
+
+-- name: DeleteChirpTags :exec
+DELETE FROM chirp_tags WHERE chirp_id = $1;
+
+-- name: GetChirpsByTag :many
+SELECT chirps.* FROM chirps
+JOIN chirp_tags ON chirp_tags.chirp_id = chirps.id
+WHERE chirp_tags.tag = sqlc.arg('tag')
+AND chirps.deleted_at IS NULL
+AND chirps.publish_at IS NULL
+AND NOT EXISTS (
+    SELECT 1 FROM blocks
+    WHERE (blocks.blocker_id = sqlc.narg('viewer_id')::uuid AND blocks.blocked_id = chirps.user_id)
+    OR (blocks.blocker_id = chirps.user_id AND blocks.blocked_id = sqlc.narg('viewer_id')::uuid)
+)
+AND NOT EXISTS (
+    SELECT 1 FROM mutes
+    WHERE mutes.muter_id = sqlc.narg('viewer_id') AND mutes.muted_id = chirps.user_id
+)
+AND (
+    sqlc.narg('cursor_created_at')::timestamp IS NULL
+    OR (chirps.created_at, chirps.id) < (sqlc.narg('cursor_created_at'), sqlc.narg('cursor_id')::uuid)
+)
+ORDER BY chirps.created_at DESC, chirps.id DESC
+LIMIT sqlc.arg('limit');
+
+-- name: GetTrendingTags :many
+SELECT chirp_tags.tag, COUNT(*) AS chirp_count FROM chirp_tags
+JOIN chirps ON chirps.id = chirp_tags.chirp_id
+WHERE chirp_tags.created_at > sqlc.arg('since')
+AND chirps.deleted_at IS NULL
+AND chirps.publish_at IS NULL
+GROUP BY chirp_tags.tag
+ORDER BY chirp_count DESC, chirp_tags.tag ASC
+LIMIT sqlc.arg('limit');
\ No newline at end of file
@@ -1,24 +1,276 @@
 -- name: CreateChirp :one
-INSERT INTO chirps (id, created_at, updated_at, body, user_id)
+INSERT INTO chirps (id, created_at, updated_at, body, user_id, reply_to, quote_of, publish_at)
+SELECT
+    gen_random_uuid(),
+    NOW(),
+    NOW(),
+    sqlc.arg('body')::text,
+    sqlc.arg('user_id')::uuid,
+    sqlc.narg('reply_to')::uuid,
+    sqlc.narg('quote_of')::uuid,
+    sqlc.narg('publish_at')::timestamp
+WHERE NOT EXISTS (
+    SELECT 1 FROM chirps AS parent
+    JOIN blocks ON blocks.blocker_id = parent.user_id
+    WHERE parent.id = sqlc.narg('reply_to') AND blocks.blocked_id = sqlc.arg('user_id')
+)
+RETURNING *;
+
+-- name: CreateRechirp :one
+INSERT INTO chirps (id, created_at, updated_at, body, user_id, rechirp_of)
 VALUES (
     gen_random_uuid(),
     NOW(),
     NOW(),
+    '',
     $1,
     $2
 )
+ON CONFLICT (user_id, rechirp_of) WHERE rechirp_of IS NOT NULL AND deleted_at IS NULL
+DO NOTHING
 RETURNING *;
 
--- name: GetChirps :many
-SELECT * FROM chirps ORDER BY created_at ASC;
+-- name: ListChirps :many
+SELECT * FROM chirps
+WHERE deleted_at IS NULL
+AND publish_at IS NULL
+AND (sqlc.narg('author_id')::uuid IS NULL OR user_id = sqlc.narg('author_id'))
+AND NOT EXISTS (
+    SELECT 1 FROM blocks
+    WHERE (blocks.blocker_id = sqlc.narg('viewer_id')::uuid AND blocks.blocked_id = chirps.user_id)
+    OR (blocks.blocker_id = chirps.user_id AND blocks.blocked_id = sqlc.narg('viewer_id')::uuid)
+)
+AND (
+    sqlc.narg('author_id') IS NOT NULL
+    OR NOT EXISTS (
+        SELECT 1 FROM mutes
+        WHERE mutes.muter_id = sqlc.narg('viewer_id') AND mutes.muted_id = chirps.user_id
+    )
+)
+AND (
+    sqlc.narg('cursor_created_at')::timestamp IS NULL
+    OR (created_at, id) > (sqlc.narg('cursor_created_at'), sqlc.narg('cursor_id')::uuid)
+)
+ORDER BY created_at ASC, id ASC
+LIMIT sqlc.narg('limit');
 
--- name: GetChirpsByAuthor :many
-SELECT * FROM chirps 
-WHERE user_id = $1
-ORDER BY created_at ASC;
+-- name: ListChirpsDesc :many
+SELECT * FROM chirps
+WHERE deleted_at IS NULL
+AND publish_at IS NULL
+AND (sqlc.narg('author_id')::uuid IS NULL OR user_id = sqlc.narg('author_id'))
+AND NOT EXISTS (
+    SELECT 1 FROM blocks
+    WHERE (blocks.blocker_id = sqlc.narg('viewer_id')::uuid AND blocks.blocked_id = chirps.user_id)
+    OR (blocks.blocker_id = chirps.user_id AND blocks.blocked_id = sqlc.narg('viewer_id')::uuid)
+)
+AND (
+    sqlc.narg('author_id') IS NOT NULL
+    OR NOT EXISTS (
+        SELECT 1 FROM mutes
+        WHERE mutes.muter_id = sqlc.narg('viewer_id') AND mutes.muted_id = chirps.user_id
+    )
+)
+AND (
+    sqlc.narg('cursor_created_at')::timestamp IS NULL
+    OR (created_at, id) < (sqlc.narg('cursor_created_at'), sqlc.narg('cursor_id')::uuid)
+)
+ORDER BY created_at DESC, id DESC
+LIMIT sqlc.narg('limit');
+
+-- name: GetTimeline :many
+SELECT chirps.* FROM chirps
+JOIN (
+    SELECT followee_id AS author_id FROM follows WHERE follower_id = sqlc.arg('user_id')
+    UNION ALL
+    SELECT sqlc.arg('user_id')::uuid
+) AS authors ON chirps.user_id = authors.author_id
+WHERE chirps.deleted_at IS NULL
+AND chirps.publish_at IS NULL
+AND NOT EXISTS (
+    SELECT 1 FROM blocks
+    WHERE (blocks.blocker_id = sqlc.arg('user_id') AND blocks.blocked_id = chirps.user_id)
+    OR (blocks.blocker_id = chirps.user_id AND blocks.blocked_id = sqlc.arg('user_id'))
+)
+AND NOT EXISTS (
+    SELECT 1 FROM mutes
+    WHERE mutes.muter_id = sqlc.arg('user_id') AND mutes.muted_id = chirps.user_id
+)
+AND (
+    sqlc.narg('cursor_created_at')::timestamp IS NULL
+    OR (chirps.created_at, chirps.id) < (sqlc.narg('cursor_created_at'), sqlc.narg('cursor_id')::uuid)
+)
+ORDER BY chirps.created_at DESC, chirps.id DESC
+LIMIT sqlc.arg('limit');
+
+-- name: SearchChirps :many
+SELECT
+    sqlc.embed(chirps),
+    (
+        ts_rank(chirps.search_vector, query)
+        * CASE WHEN users.is_chirpy_red THEN sqlc.arg('red_boost')::real ELSE 1 END
+    )::real AS rank,
+    ts_headline(
+        'english',
+        translate(chirps.body, chr(2) || chr(3), ''),
+        query,
+        'StartSel=' || chr(2) || ', StopSel=' || chr(3) || ', HighlightAll=true'
+    )::text AS highlight
+FROM chirps
+JOIN users ON users.id = chirps.user_id
+CROSS JOIN websearch_to_tsquery('english', sqlc.arg('query')) AS query
+WHERE chirps.search_vector @@ query
+AND chirps.deleted_at IS NULL
+AND chirps.publish_at IS NULL
+AND (sqlc.narg('author_id')::uuid IS NULL OR chirps.user_id = sqlc.narg('author_id'))
+AND NOT EXISTS (
+    SELECT 1 FROM blocks
+    WHERE (blocks.blocker_id = sqlc.narg('viewer_id')::uuid AND blocks.blocked_id = chirps.user_id)
+    OR (blocks.blocker_id = chirps.user_id AND blocks.blocked_id = sqlc.narg('viewer_id')::uuid)
+)
+AND NOT EXISTS (
+    SELECT 1 FROM mutes
+    WHERE mutes.muter_id = sqlc.narg('viewer_id') AND mutes.muted_id = chirps.user_id
+)
+ORDER BY rank DESC, chirps.created_at DESC, chirps.id DESC
+LIMIT sqlc.arg('limit');
 
 -- name: GetChirp :one
-SELECT * FROM chirps WHERE id = $1;
+SELECT * FROM chirps WHERE id = $1 AND deleted_at IS NULL AND publish_at IS NULL;
+
+-- name: GetChirpsByIDs :many
+SELECT * FROM chirps
+WHERE id = ANY(sqlc.arg('ids')::uuid[])
+AND deleted_at IS NULL
+AND publish_at IS NULL
+AND NOT EXISTS (
+    SELECT 1 FROM blocks
+    WHERE (blocks.blocker_id = sqlc.narg('viewer_id')::uuid AND blocks.blocked_id = chirps.user_id)
+    OR (blocks.blocker_id = chirps.user_id AND blocks.blocked_id = sqlc.narg('viewer_id')::uuid)
+);
+
+-- name: GetChirpForUpdate :one
+SELECT * FROM chirps
+WHERE id = $1 AND deleted_at IS NULL AND publish_at IS NULL
+FOR UPDATE;
+
+-- name: UpdateChirpBody :one
+UPDATE chirps
+SET body = $1, updated_at = NOW()
+WHERE id = $2
+RETURNING *;
+
+-- name: CountChirpReplies :many
+SELECT reply_to, COUNT(*) AS reply_count FROM chirps
+WHERE reply_to = ANY(sqlc.arg('chirp_ids')::uuid[])
+AND deleted_at IS NULL
+AND publish_at IS NULL
+AND NOT EXISTS (
+    SELECT 1 FROM blocks
+    WHERE (blocks.blocker_id = sqlc.narg('viewer_id')::uuid AND blocks.blocked_id = chirps.user_id)
+    OR (blocks.blocker_id = chirps.user_id AND blocks.blocked_id = sqlc.narg('viewer_id')::uuid)
+)
+GROUP BY reply_to;
+
+-- name: GetChirpThreadRoot :one
+WITH RECURSIVE ancestors AS (
+    SELECT chirps.id, chirps.reply_to, 0 AS depth FROM chirps
+    WHERE chirps.id = sqlc.arg('chirp_id')
+    AND chirps.deleted_at IS NULL AND chirps.publish_at IS NULL
+    UNION ALL
+    SELECT chirps.id, chirps.reply_to, ancestors.depth + 1 FROM chirps
+    JOIN ancestors ON chirps.id = ancestors.reply_to
+    WHERE chirps.deleted_at IS NULL AND chirps.publish_at IS NULL
+)
+SELECT id FROM ancestors
+ORDER BY depth DESC
+LIMIT 1;
+
+-- name: GetChirpReplyTree :many
+WITH RECURSIVE thread AS (
+    SELECT chirps.*, 0 AS depth FROM chirps
+    WHERE chirps.id = sqlc.arg('root_id')
+    AND chirps.deleted_at IS NULL AND chirps.publish_at IS NULL
+    AND NOT EXISTS (
+        SELECT 1 FROM blocks
+        WHERE (blocks.blocker_id = sqlc.narg('viewer_id')::uuid AND blocks.blocked_id = chirps.user_id)
+        OR (blocks.blocker_id = chirps.user_id AND blocks.blocked_id = sqlc.narg('viewer_id')::uuid)
+    )
+    UNION ALL
+    SELECT chirps.*, thread.depth + 1 FROM chirps
+    JOIN thread ON chirps.reply_to = thread.id
+    WHERE thread.depth < sqlc.arg('max_depth')::int
+    AND chirps.deleted_at IS NULL AND chirps.publish_at IS NULL
+    AND NOT EXISTS (
+        SELECT 1 FROM blocks
+        WHERE (blocks.blocker_id = sqlc.narg('viewer_id')::uuid AND blocks.blocked_id = chirps.user_id)
+        OR (blocks.blocker_id = chirps.user_id AND blocks.blocked_id = sqlc.narg('viewer_id')::uuid)
+    )
+)
+SELECT * FROM thread
+ORDER BY depth ASC, created_at ASC, id ASC;
+
+-- name: SoftDeleteChirp :exec
+UPDATE chirps
+SET deleted_at = NOW()
+WHERE id = $1 AND deleted_at IS NULL;
+
+-- name: GetDeletedChirp :one
+SELECT * FROM chirps WHERE id = $1 AND deleted_at IS NOT NULL AND hidden_at IS NULL;
+
+-- name: RestoreChirp :one
+UPDATE chirps
+SET deleted_at = NULL
+WHERE id = sqlc.arg('id') AND deleted_at > sqlc.arg('deleted_after') AND hidden_at IS NULL
+RETURNING *;
+
+-- name: PurgeDeletedChirps :execrows
+DELETE FROM chirps WHERE deleted_at < sqlc.arg('deleted_before') AND hidden_at IS NULL;
+
+-- name: HideChirp :one
+UPDATE chirps
+SET deleted_at = COALESCE(deleted_at, NOW()), hidden_at = NOW()
+WHERE id = $1 AND hidden_at IS NULL
+RETURNING *;
+
+-- name: UnhideChirp :one
+UPDATE chirps
+SET deleted_at = CASE WHEN deleted_at = hidden_at THEN NULL ELSE deleted_at END, hidden_at = NULL
+WHERE id = $1 AND hidden_at IS NOT NULL
+RETURNING *;
+
+-- name: ListScheduledChirps :many
+SELECT * FROM chirps
+WHERE user_id = $1
+AND deleted_at IS NULL
+AND publish_at IS NOT NULL
+ORDER BY publish_at ASC, id ASC;
+
+-- name: DeleteScheduledChirp :one
+DELETE FROM chirps
+WHERE id = $1 AND user_id = $2 AND publish_at IS NOT NULL
+RETURNING *;
+
+-- name: PublishDueChirps :many
+WITH due AS (
+    SELECT id FROM chirps
+    WHERE publish_at <= NOW() AND deleted_at IS NULL
+    ORDER BY publish_at ASC
+    LIMIT sqlc.arg('limit')
+    FOR UPDATE SKIP LOCKED
+)
+UPDATE chirps
+SET publish_at = NULL, created_at = NOW(), updated_at = NOW()
+FROM due
+WHERE chirps.id = due.id
+RETURNING chirps.*;
 
--- name: DeleteChirp :exec
-DELETE FROM chirps WHERE id = $1;
\ No newline at end of file
+-- name: GetRecentChirpsByUser :many
+SELECT body, created_at FROM chirps
+WHERE user_id = sqlc.arg('user_id')
+AND created_at >= sqlc.arg('since')
+AND created_at <= sqlc.arg('until')
+AND id <> sqlc.arg('exclude_id')
+AND rechirp_of IS NULL
+ORDER BY created_at DESC
+LIMIT 500;
\ No newline at end of file
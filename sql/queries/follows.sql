@@ -0,0 +1,17 @@
+-- name: FollowUser :execrows
+INSERT INTO follows (follower_id, followee_id, created_at)
+SELECT sqlc.arg('follower_id')::uuid, sqlc.arg('followee_id')::uuid, NOW()
+WHERE NOT EXISTS (
+    SELECT 1 FROM blocks
+    WHERE blocker_id = sqlc.arg('followee_id') AND blocked_id = sqlc.arg('follower_id')
+)
+ON CONFLICT (follower_id, followee_id) DO NOTHING;
+
+-- name: UnfollowUser :execrows
+DELETE FROM follows
+WHERE follower_id = $1 AND followee_id = $2;
+
+-- name: DeleteFollowsBetween :exec
+DELETE FROM follows
+WHERE (follower_id = sqlc.arg('user_id') AND followee_id = sqlc.arg('other_user_id'))
+OR (follower_id = sqlc.arg('other_user_id') AND followee_id = sqlc.arg('user_id'));
\ No newline at end of file
@@ -0,0 +1,33 @@
+-- name: CreateChirpLinks :exec
+INSERT INTO chirp_links (chirp_id, position, url)
+SELECT sqlc.arg('chirp_id')::uuid, links.ordinality - 1, links.url
+FROM unnest(sqlc.arg('urls')::text[]) WITH ORDINALITY AS links(url, ordinality);
+
+-- name: DeleteChirpLinks :exec
+DELETE FROM chirp_links WHERE chirp_id = $1;
+
+-- name: GetStaleLinkPreviewURLs :many
+SELECT urls.url::text FROM unnest(sqlc.arg('urls')::text[]) AS urls(url)
+WHERE NOT EXISTS (
+    SELECT 1 FROM link_previews
+    WHERE link_previews.url = urls.url
+    AND link_previews.fetched_at > sqlc.arg('fetched_after')
+);
+
+-- name: UpsertLinkPreview :exec
+INSERT INTO link_previews (url, fetched_at, ok, title, description, image_url, site_name)
+VALUES ($1, NOW(), $2, $3, $4, $5, $6)
+ON CONFLICT (url) DO UPDATE
+SET fetched_at = EXCLUDED.fetched_at,
+    ok = EXCLUDED.ok,
+    title = EXCLUDED.title,
+    description = EXCLUDED.description,
+    image_url = EXCLUDED.image_url,
+    site_name = EXCLUDED.site_name;
+
+-- name: GetChirpLinkPreviews :many
+SELECT DISTINCT ON (chirp_links.chirp_id) link_previews.*, chirp_links.chirp_id FROM chirp_links
+JOIN link_previews ON link_previews.url = chirp_links.url
+WHERE chirp_links.chirp_id = ANY(sqlc.arg('chirp_ids')::uuid[])
+AND link_previews.ok
+ORDER BY chirp_links.chirp_id, chirp_links.position;
\ No newline at end of file
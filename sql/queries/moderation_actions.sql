@@ -0,0 +1,21 @@
+-- name: CreateModerationAction :exec
+INSERT INTO moderation_actions (id, created_at, moderator_id, action, chirp_id, user_id, report_id, reason)
+VALUES (
+    gen_random_uuid(),
+    NOW(),
+    $1,
+    $2,
+    $3,
+    $4,
+    $5,
+    $6
+);
+
+-- name: ListModerationActions :many
+SELECT * FROM moderation_actions
+WHERE (
+    sqlc.narg('cursor_created_at')::timestamp IS NULL
+    OR (created_at, id) < (sqlc.narg('cursor_created_at'), sqlc.narg('cursor_id')::uuid)
+)
+ORDER BY created_at DESC, id DESC
+LIMIT sqlc.arg('limit');
\ No newline at end of file
@@ -0,0 +1,43 @@
+-- name: CreateModerationDecision :one
+INSERT INTO moderation_decisions (id, created_at, chirp_id, user_id, rule, reason)
+VALUES (
+    gen_random_uuid(),
+    NOW(),
+    $1,
+    $2,
+    $3,
+    $4
+)
+RETURNING *;
+
+-- name: ListUserModerationDecisions :many
+SELECT * FROM moderation_decisions
+WHERE user_id = sqlc.arg('user_id')
+AND (
+    sqlc.narg('cursor_created_at')::timestamp IS NULL
+    OR (created_at, id) < (sqlc.narg('cursor_created_at'), sqlc.narg('cursor_id')::uuid)
+)
+ORDER BY created_at DESC, id DESC
+LIMIT sqlc.arg('limit');
+
+-- name: AppealModerationDecision :one
+UPDATE moderation_decisions
+SET appeal_status = 'pending', appeal_message = $1, appealed_at = NOW()
+WHERE id = $2 AND user_id = $3 AND appeal_status = 'none'
+RETURNING *;
+
+-- name: ListPendingAppeals :many
+SELECT * FROM moderation_decisions
+WHERE appeal_status = 'pending'
+AND (
+    sqlc.narg('cursor_appealed_at')::timestamp IS NULL
+    OR (appealed_at, id) > (sqlc.narg('cursor_appealed_at'), sqlc.narg('cursor_id')::uuid)
+)
+ORDER BY appealed_at ASC, id ASC
+LIMIT sqlc.arg('limit');
+
+-- name: ResolveAppeal :one
+UPDATE moderation_decisions
+SET appeal_status = $1, resolved_at = NOW(), resolved_by = $2
+WHERE id = $3 AND appeal_status = 'pending'
+RETURNING *;
\ No newline at end of file
@@ -0,0 +1,52 @@
+-- name: CreatePoll :exec
+INSERT INTO polls (chirp_id, created_at, expires_at)
+VALUES (
+    $1,
+    NOW(),
+    $2
+);
+
+-- name: CreatePollOptions :exec
+INSERT INTO poll_options (chirp_id, position, text)
+SELECT sqlc.arg('chirp_id')::uuid, options.ordinality - 1, options.text
+FROM unnest(sqlc.arg('texts')::text[]) WITH ORDINALITY AS options(text, ordinality);
+
+-- name: GetPoll :one
+SELECT * FROM polls WHERE chirp_id = $1;
+
+-- name: GetPollForUpdate :one
+SELECT * FROM polls WHERE chirp_id = $1 FOR UPDATE;
+
+-- name: CountPollVotes :one
+SELECT COUNT(*) FROM poll_votes WHERE chirp_id = $1;
+
+-- name: DeletePoll :exec
+DELETE FROM polls WHERE chirp_id = $1;
+
+-- name: GetPollOptions :many
+SELECT
+    polls.chirp_id,
+    polls.expires_at,
+    poll_options.position,
+    poll_options.text,
+    COUNT(poll_votes.user_id) AS vote_count
+FROM polls
+JOIN poll_options ON poll_options.chirp_id = polls.chirp_id
+LEFT JOIN poll_votes ON poll_votes.chirp_id = poll_options.chirp_id
+    AND poll_votes.position = poll_options.position
+WHERE polls.chirp_id = ANY(sqlc.arg('chirp_ids')::uuid[])
+GROUP BY polls.chirp_id, poll_options.chirp_id, poll_options.position
+ORDER BY polls.chirp_id, poll_options.position;
+
+-- name: GetPollVotesByUser :many
+SELECT * FROM poll_votes
+WHERE user_id = sqlc.arg('user_id')
+AND chirp_id = ANY(sqlc.arg('chirp_ids')::uuid[]);
+
+-- name: CreatePollVote :one
+INSERT INTO poll_votes (chirp_id, user_id, position, created_at)
+SELECT polls.chirp_id, sqlc.arg('user_id')::uuid, sqlc.arg('position')::int, NOW()
+FROM polls
+WHERE polls.chirp_id = sqlc.arg('chirp_id') AND polls.expires_at > NOW()
+FOR SHARE
+RETURNING *;
\ No newline at end of file
@@ -1,17 +1,25 @@
 -- name: CreateRefreshToken :one
 INSERT INTO refresh_tokens (
-    token,
+    token_hash,
     user_id,
     created_at,
     updated_at,
-    expires_at
+    expires_at,
+    family_id,
+    user_agent,
+    ip,
+    last_used_at
 )
 VALUES (
     $1,
     $2,
     NOW(),
     NOW(),
-    $3
+    $3,
+    $4,
+    $5,
+    $6,
+    NOW()
 )
 RETURNING *;
 
@@ -19,12 +27,43 @@ RETURNING *;
 -- name: RevokeRefreshToken :one
 UPDATE refresh_tokens
 SET revoked_at = NOW(), updated_at = NOW()
-WHERE token = $1
+WHERE token_hash = $1
 RETURNING *;
 
--- name: GetUserFromRefreshToken :one
-SELECT users.* FROM users
-JOIN refresh_tokens ON users.id = refresh_tokens.user_id
-WHERE refresh_tokens.token $1
+-- name: GetRefreshTokenForUpdate :one
+SELECT * FROM refresh_tokens
+WHERE token_hash = $1
+FOR UPDATE;
+
+-- name: RotateRefreshToken :exec
+UPDATE refresh_tokens
+SET rotated_at = NOW(), revoked_at = NOW(), updated_at = NOW(), last_used_at = NOW()
+WHERE token_hash = $1;
+
+-- name: RevokeRefreshTokenFamily :execrows
+UPDATE refresh_tokens
+SET revoked_at = NOW(), updated_at = NOW()
+WHERE family_id = $1 AND revoked_at IS NULL;
+
+-- name: ListUserSessions :many
+SELECT
+    refresh_tokens.*,
+    (
+        SELECT MIN(family.created_at) FROM refresh_tokens AS family
+        WHERE family.family_id = refresh_tokens.family_id
+    )::timestamp AS signed_in_at
+FROM refresh_tokens
+WHERE user_id = $1
 AND revoked_at IS NULL
-AND expires_at > NOW();
\ No newline at end of file
+AND expires_at > NOW()
+ORDER BY last_used_at DESC;
+
+-- name: RevokeUserSession :execrows
+UPDATE refresh_tokens
+SET revoked_at = NOW(), updated_at = NOW()
+WHERE family_id = $1 AND user_id = $2 AND revoked_at IS NULL;
+
+-- name: RevokeUserSessions :execrows
+UPDATE refresh_tokens
+SET revoked_at = NOW(), updated_at = NOW()
+WHERE user_id = $1 AND revoked_at IS NULL;
\ No newline at end of file
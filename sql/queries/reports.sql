@@ -0,0 +1,37 @@
+-- name: CreateReport :one
+INSERT INTO reports (id, created_at, chirp_id, reporter_id, reason, details)
+VALUES (
+    gen_random_uuid(),
+    NOW(),
+    $1,
+    $2,
+    $3,
+    $4
+)
+RETURNING *;
+
+-- name: ListReports :many
+SELECT reports.*, chirps.body AS chirp_body, chirps.user_id AS chirp_user_id FROM reports
+JOIN chirps ON chirps.id = reports.chirp_id
+WHERE reports.status = sqlc.arg('status')
+AND (
+    sqlc.narg('cursor_created_at')::timestamp IS NULL
+    OR (reports.created_at, reports.id) > (sqlc.narg('cursor_created_at'), sqlc.narg('cursor_id')::uuid)
+)
+ORDER BY reports.created_at ASC, reports.id ASC
+LIMIT sqlc.arg('limit');
+
+-- name: DismissReport :one
+UPDATE reports
+SET status = 'dismissed', resolved_at = NOW(), resolved_by = $1
+WHERE id = $2 AND status = 'open'
+RETURNING *;
+
+-- name: ResolveChirpReports :execrows
+UPDATE reports
+SET status = 'resolved', resolved_at = NOW(), resolved_by = $1
+WHERE chirp_id = $2 AND status = 'open';
+
+-- name: CountOpenChirpReports :one
+SELECT COUNT(*) FROM reports
+WHERE chirp_id = $1 AND status = 'open';
\ No newline at end of file
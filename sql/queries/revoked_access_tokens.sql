@@ -0,0 +1,13 @@
+-- name: RevokeAccessToken :exec
+INSERT INTO revoked_access_tokens (jti, user_id, revoked_at, expires_at)
+VALUES ($1, $2, NOW(), $3)
+ON CONFLICT (jti) DO NOTHING;
+
+-- name: IsAccessTokenRevoked :one
+SELECT EXISTS (
+    SELECT 1 FROM revoked_access_tokens
+    WHERE jti = $1
+);
+
+-- name: PurgeRevokedAccessTokens :execrows
+DELETE FROM revoked_access_tokens WHERE expires_at < NOW();
\ No newline at end of file
@@ -15,12 +15,49 @@ SET email = $1, hashed_password = $2, updated_at = NOW()
 WHERE id = $3
 RETURNING *;
 
+-- name: GetUser :one
+SELECT * FROM users
+WHERE id = $1;
+
 -- name: GetUserByEmail :one
 SELECT * FROM users
 WHERE email = $1;
 
+-- name: GetUsersByEmails :many
+SELECT * FROM users
+WHERE lower(email) = ANY(sqlc.arg('emails')::text[]);
+
 -- name: UpgradeUserToChirpyRed :one
 UPDATE users
 SET is_chirpy_red = true, updated_at = NOW()
 WHERE id = $1
-RETURNING *;
\ No newline at end of file
+RETURNING *;
+
+-- name: IsUserSuspended :one
+SELECT EXISTS (
+    SELECT 1 FROM users
+    WHERE id = $1
+    AND suspended_at IS NOT NULL
+    AND (suspended_until IS NULL OR suspended_until > NOW())
+);
+
+-- name: SuspendUser :one
+UPDATE users
+SET suspended_at = NOW(), suspended_until = $1, suspension_reason = $2, updated_at = NOW()
+WHERE id = $3
+RETURNING *;
+
+-- name: UnsuspendUser :one
+UPDATE users
+SET suspended_at = NULL, suspended_until = NULL, suspension_reason = '', updated_at = NOW()
+WHERE id = $1 AND suspended_at IS NOT NULL
+RETURNING *;
+
+-- name: SetUserSessionsNotBefore :exec
+UPDATE users
+SET sessions_not_before = sqlc.arg('sessions_not_before'), updated_at = NOW()
+WHERE id = sqlc.arg('id');
+
+-- name: GetUserSessionsNotBefore :one
+SELECT sessions_not_before FROM users
+WHERE id = $1;
\ No newline at end of file
@@ -0,0 +1,9 @@
+-- +goose Up
+ALTER TABLE chirps
+ADD COLUMN reply_to UUID REFERENCES chirps ON DELETE SET NULL;
+
+CREATE INDEX chirps_reply_to_idx ON chirps (reply_to);
+
+-- +goose Down
+ALTER TABLE chirps
+DROP COLUMN reply_to;
\ No newline at end of file
@@ -0,0 +1,12 @@
+-- +goose Up
+CREATE TABLE chirp_likes (
+    user_id UUID NOT NULL REFERENCES users ON DELETE CASCADE,
+    chirp_id UUID NOT NULL REFERENCES chirps ON DELETE CASCADE,
+    created_at TIMESTAMP NOT NULL,
+    PRIMARY KEY (user_id, chirp_id)
+);
+
+CREATE INDEX chirp_likes_chirp_id_created_at_idx ON chirp_likes (chirp_id, created_at);
+
+-- +goose Down
+DROP TABLE chirp_likes;
\ No newline at end of file
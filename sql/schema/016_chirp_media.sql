@@ -0,0 +1,19 @@
+-- +goose Up
+CREATE TABLE chirp_media (
+    id UUID PRIMARY KEY,
+    chirp_id UUID NOT NULL REFERENCES chirps ON DELETE CASCADE,
+    position INTEGER NOT NULL,
+    storage_key TEXT NOT NULL,
+    thumbnail_key TEXT NOT NULL,
+    content_type TEXT NOT NULL,
+    width INTEGER NOT NULL,
+    height INTEGER NOT NULL,
+    thumbnail_width INTEGER NOT NULL,
+    thumbnail_height INTEGER NOT NULL,
+    alt_text TEXT NOT NULL,
+    created_at TIMESTAMP NOT NULL,
+    UNIQUE (chirp_id, position)
+);
+
+-- +goose Down
+DROP TABLE chirp_media;
\ No newline at end of file
@@ -0,0 +1,14 @@
+-- +goose Up
+-- No foreign keys on purpose: once the original is purged the reference
+-- must survive, so the rechirp or quote can render it as a tombstone.
+ALTER TABLE chirps
+ADD COLUMN rechirp_of UUID,
+ADD COLUMN quote_of UUID;
+
+CREATE UNIQUE INDEX chirps_user_id_rechirp_of_idx ON chirps (user_id, rechirp_of)
+WHERE rechirp_of IS NOT NULL AND deleted_at IS NULL;
+
+-- +goose Down
+ALTER TABLE chirps
+DROP COLUMN quote_of,
+DROP COLUMN rechirp_of;
\ No newline at end of file
@@ -0,0 +1,17 @@
+-- +goose Up
+-- Drafts live apart from chirps so they can never leak into a listing.
+-- reply_to and quote_of are only checked when the draft is published.
+CREATE TABLE drafts (
+    id UUID PRIMARY KEY,
+    created_at TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP NOT NULL,
+    user_id UUID NOT NULL REFERENCES users ON DELETE CASCADE,
+    body TEXT NOT NULL,
+    reply_to UUID,
+    quote_of UUID
+);
+
+CREATE INDEX drafts_user_id_updated_at_idx ON drafts (user_id, updated_at);
+
+-- +goose Down
+DROP TABLE drafts;
\ No newline at end of file
@@ -0,0 +1,26 @@
+-- +goose Up
+CREATE TABLE bookmark_collections (
+    id UUID PRIMARY KEY,
+    created_at TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP NOT NULL,
+    user_id UUID NOT NULL REFERENCES users ON DELETE CASCADE,
+    name TEXT NOT NULL,
+    UNIQUE (user_id, name)
+);
+
+-- A bookmark sits in at most one collection. Deleting the collection
+-- keeps its bookmarks.
+CREATE TABLE bookmarks (
+    user_id UUID NOT NULL REFERENCES users ON DELETE CASCADE,
+    chirp_id UUID NOT NULL REFERENCES chirps ON DELETE CASCADE,
+    collection_id UUID REFERENCES bookmark_collections ON DELETE SET NULL,
+    created_at TIMESTAMP NOT NULL,
+    PRIMARY KEY (user_id, chirp_id)
+);
+
+CREATE INDEX bookmarks_user_id_created_at_idx ON bookmarks (user_id, created_at, chirp_id);
+CREATE INDEX bookmarks_collection_id_idx ON bookmarks (collection_id);
+
+-- +goose Down
+DROP TABLE bookmarks;
+DROP TABLE bookmark_collections;
\ No newline at end of file
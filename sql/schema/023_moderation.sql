@@ -0,0 +1,54 @@
+-- +goose Up
+-- A suspension without suspended_until lasts until it is lifted.
+ALTER TABLE users
+ADD COLUMN suspended_at TIMESTAMP,
+ADD COLUMN suspended_until TIMESTAMP,
+ADD COLUMN suspension_reason TEXT NOT NULL DEFAULT '';
+
+-- Hidden chirps also have deleted_at set, so every query that skips
+-- deleted chirps skips them too. Only a moderator can bring them back
+-- and the purger leaves them alone.
+ALTER TABLE chirps
+ADD COLUMN hidden_at TIMESTAMP;
+
+CREATE TABLE reports (
+    id UUID PRIMARY KEY,
+    created_at TIMESTAMP NOT NULL,
+    chirp_id UUID NOT NULL REFERENCES chirps ON DELETE CASCADE,
+    reporter_id UUID NOT NULL REFERENCES users ON DELETE CASCADE,
+    reason TEXT NOT NULL,
+    details TEXT NOT NULL,
+    status TEXT NOT NULL DEFAULT 'open',
+    resolved_at TIMESTAMP,
+    resolved_by UUID REFERENCES users ON DELETE SET NULL,
+    UNIQUE (chirp_id, reporter_id)
+);
+
+CREATE INDEX reports_status_created_at_idx ON reports (status, created_at, id);
+
+-- The audit log has no foreign keys so entries outlive the rows they
+-- are about.
+CREATE TABLE moderation_actions (
+    id UUID PRIMARY KEY,
+    created_at TIMESTAMP NOT NULL,
+    moderator_id UUID NOT NULL,
+    action TEXT NOT NULL,
+    chirp_id UUID,
+    user_id UUID,
+    report_id UUID,
+    reason TEXT NOT NULL
+);
+
+CREATE INDEX moderation_actions_created_at_idx ON moderation_actions (created_at, id);
+
+-- +goose Down
+DROP TABLE moderation_actions;
+DROP TABLE reports;
+
+ALTER TABLE chirps
+DROP COLUMN hidden_at;
+
+ALTER TABLE users
+DROP COLUMN suspension_reason,
+DROP COLUMN suspended_until,
+DROP COLUMN suspended_at;
\ No newline at end of file
@@ -0,0 +1,23 @@
+-- +goose Up
+-- Automatic moderation decisions. The author of the chirp can appeal a
+-- decision once and a moderator accepts or rejects the appeal.
+CREATE TABLE moderation_decisions (
+    id UUID PRIMARY KEY,
+    created_at TIMESTAMP NOT NULL,
+    chirp_id UUID NOT NULL REFERENCES chirps ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users ON DELETE CASCADE,
+    rule TEXT NOT NULL,
+    reason TEXT NOT NULL,
+    appeal_status TEXT NOT NULL DEFAULT 'none',
+    appeal_message TEXT NOT NULL DEFAULT '',
+    appealed_at TIMESTAMP,
+    resolved_at TIMESTAMP,
+    resolved_by UUID REFERENCES users ON DELETE SET NULL
+);
+
+CREATE INDEX moderation_decisions_user_id_created_at_idx ON moderation_decisions (user_id, created_at, id);
+CREATE INDEX moderation_decisions_pending_appeals_idx ON moderation_decisions (appealed_at, id)
+WHERE appeal_status = 'pending';
+
+-- +goose Down
+DROP TABLE moderation_decisions;
\ No newline at end of file
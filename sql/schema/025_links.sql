@@ -0,0 +1,26 @@
+-- +goose Up
+CREATE TABLE chirp_links (
+    chirp_id UUID NOT NULL REFERENCES chirps ON DELETE CASCADE,
+    position INTEGER NOT NULL,
+    url TEXT NOT NULL,
+    PRIMARY KEY (chirp_id, position)
+);
+
+CREATE INDEX chirp_links_url_idx ON chirp_links (url);
+
+-- Previews are shared by every chirp linking to the same URL. Failed
+-- fetches are stored too, with ok = false, so they aren't retried for
+-- every chirp.
+CREATE TABLE link_previews (
+    url TEXT PRIMARY KEY,
+    fetched_at TIMESTAMP NOT NULL,
+    ok BOOLEAN NOT NULL,
+    title TEXT NOT NULL,
+    description TEXT NOT NULL,
+    image_url TEXT NOT NULL,
+    site_name TEXT NOT NULL
+);
+
+-- +goose Down
+DROP TABLE link_previews;
+DROP TABLE chirp_links;
\ No newline at end of file
@@ -0,0 +1,20 @@
+-- +goose Up
+-- Every login starts a family of refresh tokens. Each refresh rotates
+-- the token: the old one gets rotated_at and revoked_at and a new one
+-- joins the family. Presenting a rotated token again means it leaked,
+-- so the whole family is revoked.
+ALTER TABLE refresh_tokens
+ADD COLUMN family_id UUID,
+ADD COLUMN rotated_at TIMESTAMP;
+
+UPDATE refresh_tokens SET family_id = gen_random_uuid();
+
+ALTER TABLE refresh_tokens
+ALTER COLUMN family_id SET NOT NULL;
+
+CREATE INDEX refresh_tokens_family_id_idx ON refresh_tokens (family_id);
+
+-- +goose Down
+ALTER TABLE refresh_tokens
+DROP COLUMN rotated_at,
+DROP COLUMN family_id;
\ No newline at end of file
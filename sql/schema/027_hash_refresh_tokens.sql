@@ -0,0 +1,16 @@
+-- +goose Up
+-- Refresh tokens are stored as hex encoded SHA-256 digests. Existing
+-- tokens are hashed in place, so sessions survive the migration. The
+-- primary key on token_hash is the lookup index.
+ALTER TABLE refresh_tokens
+RENAME COLUMN token TO token_hash;
+
+UPDATE refresh_tokens
+SET token_hash = encode(sha256(convert_to(token_hash, 'UTF8')), 'hex');
+
+-- +goose Down
+-- Digests can't be turned back into tokens, so every session ends.
+DELETE FROM refresh_tokens;
+
+ALTER TABLE refresh_tokens
+RENAME COLUMN token_hash TO token;
\ No newline at end of file
@@ -0,0 +1,21 @@
+-- +goose Up
+-- Access tokens aren't stored, so revoking one means remembering its
+-- jti until it would have expired anyway. sessions_not_before revokes
+-- every access token of a user issued before it at once.
+CREATE TABLE revoked_access_tokens (
+    jti UUID PRIMARY KEY,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    revoked_at TIMESTAMP NOT NULL,
+    expires_at TIMESTAMP NOT NULL
+);
+
+CREATE INDEX revoked_access_tokens_expires_at_idx ON revoked_access_tokens (expires_at);
+
+ALTER TABLE users
+ADD COLUMN sessions_not_before TIMESTAMP;
+
+-- +goose Down
+ALTER TABLE users
+DROP COLUMN sessions_not_before;
+
+DROP TABLE revoked_access_tokens;
\ No newline at end of file
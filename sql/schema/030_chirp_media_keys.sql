@@ -0,0 +1,8 @@
+-- +goose Up
+-- Media is served by looking up the chirp a storage key belongs to.
+CREATE INDEX chirp_media_storage_key_idx ON chirp_media (storage_key);
+CREATE INDEX chirp_media_thumbnail_key_idx ON chirp_media (thumbnail_key);
+
+-- +goose Down
+DROP INDEX chirp_media_thumbnail_key_idx;
+DROP INDEX chirp_media_storage_key_idx;
\ No newline at end of file
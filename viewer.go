@@ -0,0 +1,29 @@
+package main
+
+import (
+	"net/http"
+
+	"github.com/DanilShapilov/chirpy/internal/auth"
+	"github.com/google/uuid"
+)
+
+// viewerID returns the ID of the user behind an optional bearer token.
+// Public endpoints use it to personalize responses, so a missing or
+// invalid token just means an anonymous viewer (uuid.Nil).
+func (cfg *apiConfig) viewerID(req *http.Request) uuid.UUID {
+	token, err := auth.GetBearerToken(req.Header)
+	if err != nil {
+		return uuid.Nil
+	}
+	userID, err := cfg.jwtKeys.ValidateJWT(req.Context(), token)
+	if err != nil {
+		return uuid.Nil
+	}
+	return userID
+}
+
+// nullViewer turns a viewer ID into a query parameter, NULL for
+// anonymous viewers.
+func nullViewer(viewerID uuid.UUID) uuid.NullUUID {
+	return uuid.NullUUID{UUID: viewerID, Valid: viewerID != uuid.Nil}
+}